@@ -0,0 +1,107 @@
+package asset
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+)
+
+// headSize is how much of file beginning is kept for content type sniffing and image header decoding.
+const headSize = 64 << 10
+
+// analysis is everything scan needs to know about file content.
+type analysis struct {
+	// sha256 of raw content
+	digest []byte
+	// hex sha256 of hashed content, normalized if requested
+	hash          string
+	size          int64
+	contentType   string
+	width, height int
+}
+
+// analyze reads r once, computing hashes, size, content type and image dimensions together,
+// so files are never read twice during scan. If normalize is set, hash ignores UTF-8 BOM and
+// CRLF line endings. Non empty salt is mixed into hash before content.
+func analyze(r io.Reader, path string, normalize bool, salt string) (*analysis, error) {
+	head := &headBuffer{limit: headSize}
+	raw := sha256.New()
+	w := io.MultiWriter(raw, head)
+
+	result := &analysis{}
+	if normalize {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		w.Write(content)
+		result.size = int64(len(content))
+
+		sum := sha256.Sum256(normalizeText(content))
+		result.hash = hex.EncodeToString(sum[:])
+	} else {
+		n, err := io.Copy(w, r)
+		if err != nil {
+			return nil, err
+		}
+		result.size = n
+	}
+
+	result.digest = raw.Sum(nil)
+	if result.hash == "" {
+		result.hash = hex.EncodeToString(result.digest)
+	}
+	if salt != "" {
+		sum := sha256.Sum256([]byte(salt + "\x00" + result.hash))
+		result.hash = hex.EncodeToString(sum[:])
+	}
+
+	result.contentType = mime.TypeByExtension(filepath.Ext(path))
+	if result.contentType == "" {
+		result.contentType = http.DetectContentType(head.Bytes())
+	}
+
+	if decode := imageConfigDecoder(result.contentType); decode != nil {
+		if config, err := decode(bytes.NewReader(head.Bytes())); err == nil {
+			result.width, result.height = config.Width, config.Height
+		}
+	}
+
+	return result, nil
+}
+
+// imageConfigDecoder returns header decoder of image content type. Decoders are called
+// directly instead of registered with image package, so importing asset package doesn't
+// change formats known to [image.Decode] of the application.
+func imageConfigDecoder(contentType string) func(r io.Reader) (image.Config, error) {
+	switch contentType {
+	case "image/png":
+		return png.DecodeConfig
+	case "image/jpeg":
+		return jpeg.DecodeConfig
+	case "image/gif":
+		return gif.DecodeConfig
+	}
+	return nil
+}
+
+// headBuffer keeps first limit bytes written to it and discards the rest.
+type headBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if rest := b.limit - b.Len(); rest > 0 {
+		b.Buffer.Write(p[:min(rest, len(p))])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,110 @@
+package asset
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 32, 16))); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.String()
+
+	asset, err := newAsset(strings.NewReader(content), "img/logo.png", "/", 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if asset.Hash != sha256Prefix(content, 10) {
+		t.Errorf("Unexpected hash: %s\n", asset.Hash)
+	}
+	if asset.Size != int64(len(content)) {
+		t.Errorf("Unexpected size: %d\n", asset.Size)
+	}
+	if asset.ContentType != "image/png" {
+		t.Errorf("Unexpected content type: %s\n", asset.ContentType)
+	}
+	if asset.Width != 32 || asset.Height != 16 {
+		t.Errorf("Unexpected dimensions: %dx%d\n", asset.Width, asset.Height)
+	}
+}
+
+func TestAnalyzeImageFormats(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 8, 4), color.Palette{color.Black, color.White})
+	encoders := map[string]func(w io.Writer) error{
+		"logo.jpg": func(w io.Writer) error { return jpeg.Encode(w, img, nil) },
+		"logo.gif": func(w io.Writer) error { return gif.Encode(w, img, nil) },
+	}
+	for name, encode := range encoders {
+		var buf bytes.Buffer
+		if err := encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := analyze(&buf, name, false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.width != 8 || result.height != 4 {
+			t.Errorf("Unexpected dimensions of %s: %dx%d\n", name, result.width, result.height)
+		}
+	}
+}
+
+func TestAnalyzeHashCache(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 32, 16))); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// second scan reads metadata from sidecar
+	cacheDir := t.TempDir()
+	for i := range 2 {
+		a := NewAssetMapper()
+		a.Trim = dir + "/"
+		a.HashCache = true
+		a.HashCacheDir = cacheDir
+		if err := a.ScanDir(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		asset := a.Assets["logo.png"]
+		if asset.ContentType != "image/png" {
+			t.Errorf("Unexpected content type in scan %d: %s\n", i+1, asset.ContentType)
+		}
+		if asset.Width != 32 || asset.Height != 16 {
+			t.Errorf("Unexpected dimensions in scan %d: %dx%d\n", i+1, asset.Width, asset.Height)
+		}
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 512, 512))); err != nil {
+		b.Fatal(err)
+	}
+	content := buf.Bytes()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(content)))
+	for b.Loop() {
+		if _, err := analyze(bytes.NewReader(content), "logo.png", false, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
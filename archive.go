@@ -0,0 +1,100 @@
+package asset
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing/fstest"
+	"time"
+)
+
+// UseArchive maps all files from .zip, .tar.gz or .tgz archive of built assets, storing
+// its path inside archive and hash, same as [AssetMapper.ScanDir] does for directory.
+// Scan options work the same way as in [AssetMapper.ScanDir], except HashCache, which needs
+// directory on disk. Trim is applied to archive paths, which are already relative to archive
+// root, so StripScanRoot changes nothing.
+//
+// Zip archive implements [io/fs.FS], so it can be served with [AssetMapper.FileServer]:
+//
+//	assetMapper.Trim = "dist/"
+//	err := assetMapper.UseArchive("assets.zip")
+//
+//	r, _ := zip.OpenReader("assets.zip")
+//	dist, _ := fs.Sub(r, "dist")
+//	http.Handle("GET /", assetMapper.FileServer(http.FS(dist)))
+func (a *AssetMapper) UseArchive(archivePath string) error {
+	switch {
+	case strings.HasSuffix(archivePath, ".zip"):
+		return a.useZip(archivePath)
+	case strings.HasSuffix(archivePath, ".tar.gz"), strings.HasSuffix(archivePath, ".tgz"):
+		return a.useTarGz(archivePath)
+	}
+	return errors.New("unsupported archive format")
+}
+
+func (a *AssetMapper) useZip(archivePath string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return a.scanArchive(r)
+}
+
+func (a *AssetMapper) useTarGz(archivePath string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	// tar can be read only sequentially, so its files are loaded into memory to be walked as fs.FS
+	fsys := fstest.MapFS{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return a.scanArchive(fsys)
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(header.Name)
+		if header.Typeflag != tar.TypeReg || !fs.ValidPath(name) {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		fsys[name] = &fstest.MapFile{Data: content, Mode: fs.FileMode(header.Mode).Perm(), ModTime: header.ModTime}
+	}
+}
+
+// scanArchive maps files of archive fsys through the same scan pipeline as directories.
+func (a *AssetMapper) scanArchive(fsys fs.FS) error {
+	return a.scanFS(fsys, ".", "", time.Time{}, func(name string) string {
+		if a.Trim != "" {
+			name = strings.TrimPrefix(name, a.Trim)
+		}
+		return name
+	}, func(asset *Asset) error {
+		a.AddAsset(asset, false)
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package asset
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var archiveFixture = map[string]string{
+	"dist/css/app.css": "body { color: red; }",
+	"dist/js/app.js":   "console.log('app')",
+}
+
+func TestUseArchiveZip(t *testing.T) {
+	assertArchiveMapped(t, writeZipArchive(t, archiveFixture))
+}
+
+func TestUseArchiveOptions(t *testing.T) {
+	files := map[string]string{
+		"dist/css/app.css":         "@import 'base.css';",
+		"dist/css/base.css":        "body { color: red; }",
+		"dist/js/vendor/legacy.js": "legacy",
+	}
+
+	scanned := []string{}
+	a := NewAssetMapper()
+	a.Trim = "dist/"
+	a.MaxScanDepth = 2
+	a.CSSImportHashing = true
+	a.PostScan = func(asset *Asset) error {
+		scanned = append(scanned, asset.Path)
+		return nil
+	}
+	if err := a.UseArchive(writeZipArchive(t, files)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[css/app.css css/base.css]"
+	if result := fmt.Sprint(scanned); expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	// hash of importing file changes with imported one
+	if a.Assets["css/app.css"].Hash == sha256Prefix(files["dist/css/app.css"], a.HashLen) {
+		t.Errorf("Imported css should be folded into hash of css/app.css\n")
+	}
+}
+
+func writeZipArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	archivePath := filepath.Join(t.TempDir(), "assets.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	return archivePath
+}
+
+func TestUseArchiveTarGz(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "assets.tar.gz")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gz := gzip.NewWriter(f)
+	w := tar.NewWriter(gz)
+	for name, content := range archiveFixture {
+		header := &tar.Header{Name: "./" + name, Mode: 0o644, Size: int64(len(content))}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	gz.Close()
+	f.Close()
+
+	assertArchiveMapped(t, archivePath)
+}
+
+func assertArchiveMapped(t *testing.T, archivePath string) {
+	t.Helper()
+
+	a := NewAssetMapper()
+	a.Trim = "dist/"
+	if err := a.UseArchive(archivePath); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/css/app.css?v=" + sha256Prefix("body { color: red; }", a.HashLen)
+	result := a.Get("css/app.css")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	if _, ok := a.Assets["js/app.js"]; !ok {
+		t.Errorf("js/app.js should be mapped from archive\n")
+	}
+}
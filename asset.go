@@ -1,40 +1,80 @@
 package asset
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"bytes"
 	"io"
 	"os"
+	"time"
 )
 
 type Asset struct {
 	PublicPath string
 	Hash       string
 	Path       string
+	// File is output file path relative to public root. Empty means same as Path.
+	File string
+	// Fingerprinted is set when File name already contains version (manifest outputs),
+	// such assets are served as immutable and don't get version query.
+	Fingerprinted bool
+	// ModTime is file modification time, zero if unknown
+	ModTime time.Time
+	// Size is file size in bytes, zero if unknown
+	Size int64
+	// ContentType is media type detected from file extension or content, empty if unknown
+	ContentType string
+	// Width and Height are image dimensions in pixels, zero if unknown
+	Width  int
+	Height int
+
+	// sha256 of raw file content, nil if file wasn't hashed, look [AssetMapper.CSPHashes]
+	digest []byte
 }
 
 func NewAsset(file *os.File, path, publicPath string, hashLen int) (*Asset, error) {
 	defer file.Close()
 
-	hash := ""
-	if hashLen > 0 {
-		hasher := sha256.New()
-		if _, err := io.Copy(hasher, file); err != nil {
-			return nil, err
-		}
+	return newAsset(file, path, publicPath, hashLen, false)
+}
 
-		hash = hex.EncodeToString(hasher.Sum(nil))
+// newAsset creates asset hashing content from r. If normalize is set, UTF-8 BOM and CRLF
+// line endings are ignored, so the same text file gets the same hash on every platform.
+func newAsset(r io.Reader, path, publicPath string, hashLen int, normalize bool) (*Asset, error) {
+	result, err := analyze(r, path, normalize, "")
+	if err != nil {
+		return nil, err
 	}
 
-	hash = hash[0:hashLen]
+	return analyzedAsset(result, path, publicPath, hashLen), nil
+}
 
+// analyzedAsset creates asset from content analysis.
+func analyzedAsset(result *analysis, path, publicPath string, hashLen int) *Asset {
 	return &Asset{
-		Path:       path,
-		Hash:       hash,
-		PublicPath: publicPath,
-	}, nil
+		Path:        path,
+		File:        path,
+		Hash:        result.hash[0:hashLen],
+		PublicPath:  publicPath,
+		Size:        result.size,
+		ContentType: result.contentType,
+		Width:       result.width,
+		Height:      result.height,
+		digest:      result.digest,
+	}
 }
 
+func normalizeText(content []byte) []byte {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// String returns public url of asset. Version query is omitted for fingerprinted assets
+// and for assets without hash (HashLen 0).
 func (a *Asset) String() string {
+	if a.Fingerprinted {
+		return joinURL(a.PublicPath, a.File)
+	}
+	if a.Hash == "" {
+		return a.PublicPath + a.Path
+	}
 	return a.PublicPath + a.Path + "?v=" + a.Hash
 }
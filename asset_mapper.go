@@ -1,32 +1,252 @@
 // Package asset provides asset mapper functionality to help using static assets (css, scripts, images)
 // in Go templates
 //
-// Package inspired by Symfony AssetMapper, only much simpler, without importmap functionality and compiling assets.
+// Package inspired by Symfony AssetMapper, only much simpler, without compiling assets. Importmap support
+// is limited to rendering static import map with [AssetMapper.ImportMapTag].
 package asset
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html"
 	"html/template"
+	"io"
 	"io/fs"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
+	pathpkg "path"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrAssetCollision is returned when different files are mapped to the same asset path.
+	ErrAssetCollision = errors.New("asset path collision")
+	// ErrPathTraversal is returned by tag helpers for paths containing ".." segments.
+	ErrPathTraversal = errors.New("path traversal is not allowed")
+	// ErrURLCollision is returned by [AssetMapper.CheckURLCollisions] when different assets share public url.
+	ErrURLCollision = errors.New("asset url collision")
+	// ErrOddAttributes is returned by tag helpers when attrs are not even number of strings.
+	ErrOddAttributes = errors.New("attrs must be an even number of strings")
+	// ErrInvalidFetchPriority is returned by tag helpers for fetchpriority other than high, low or auto.
+	ErrInvalidFetchPriority = errors.New("fetchpriority must be high, low or auto")
+	// ErrInvalidHashLen is returned by scans when HashLen or HashLenByKind is outside of 0-64,
+	// the length of hex encoded sha256.
+	ErrInvalidHashLen = errors.New("hash length must be between 0 and 64")
 )
 
 type AssetMapperEntry struct {
 	CSS []string
 	JS  []string
+	// Other holds entry files which are neither css nor js, e.g. wasm modules
+	Other []string
+	// Dir is source directory of entry module, assets from it are preferred by [AssetMapper.WithEntry]
+	Dir string
+	// Placement of js urls, scripts without placement belong to body
+	Placement map[string]ScriptPlacement
+	// Assets lists urls of static files (images, fonts) imported by entry, look [AssetMapper.HeadLinks]
+	Assets []string
+	// Prefetch lists urls of chunks loaded lazily by entry, look [AssetMapper.HeadLinks]
+	Prefetch []string
+}
+
+// IntegrityMode controls when [AssetMapper.ScriptTag] and [AssetMapper.LinkTag] add Subresource
+// Integrity attributes.
+type IntegrityMode int
+
+const (
+	// IntegrityOff never adds integrity attribute.
+	IntegrityOff IntegrityMode = iota
+	// IntegrityAlways adds integrity and crossorigin attributes to every hashed asset.
+	IntegrityAlways
+	// IntegrityCrossOrigin adds integrity and crossorigin attributes only to assets served from
+	// another origin (CDN), where SRI protects against compromised third party.
+	IntegrityCrossOrigin
+)
+
+// VersionStrategy controls how version of scanned assets is computed.
+type VersionStrategy int
+
+const (
+	// VersionStrategyContent versions assets with hash of their content.
+	VersionStrategyContent VersionStrategy = iota
+	// VersionStrategyMtime versions assets with their modification time and size, without reading
+	// files. It's much faster for huge media libraries, but version changes on every touch and
+	// content metadata, like dimensions of images, is not detected.
+	VersionStrategyMtime
+)
+
+// ScanStats counts work done by all scans of asset mapper, look [AssetMapper.Stats]. CI can
+// report them next to benchmarks to track scan performance.
+type ScanStats struct {
+	// Files is number of scanned files passed to PostScan and mapped
+	Files int
+	// HashedFiles is number of files read and hashed, not reused from HashCache
+	HashedFiles int
+	// HashedBytes is total size of hashed files
+	HashedBytes int64
+	// CacheHits is number of files with hash reused from HashCache
+	CacheHits int
+	// Errors is number of file errors collected with ContinueOnError
+	Errors int
 }
 
+// ScriptPlacement is a hint where entry script should be rendered.
+type ScriptPlacement int
+
+const (
+	ScriptPlacementBody ScriptPlacement = iota
+	ScriptPlacementHead
+)
+
 type AssetMapper struct {
 	PublicPath string
 	Assets     map[string]*Asset
 	Entries    map[string]*AssetMapperEntry
 	HashLen    int
+	// NoScript is html rendered inside <noscript> tag by [AssetMapper.NoScriptTag], or after
+	// output of [AssetMapper.ScriptTag] and entry script helpers called with "noscript" "true"
+	// attributes, for progressive enhancement fallbacks. Empty means no noscript tag.
+	NoScript template.HTML
+	// SingleQuoteAttributes renders tag attribute values in single quotes instead of double quotes
+	SingleQuoteAttributes bool
+	// TurboTrack adds data-turbo-track="reload" to entry link and script tags, so Hotwire Turbo
+	// does full page reload when asset versions change after deploy
+	TurboTrack bool
+	// Integrity adds SRI attributes to script and link tags of scanned assets, look [IntegrityMode].
+	// Attributes passed to tag helper take precedence. With RewriteCSSURLs css files are served
+	// with different content than scanned, so they get no integrity attribute.
+	Integrity IntegrityMode
+	// Origin is origin of pages, e.g. "https://example.com", used by [IntegrityCrossOrigin] to
+	// recognize absolute asset urls on the same origin. Any absolute url is cross-origin if empty.
+	Origin string
+	// BuildVersion is appended by [AssetMapper.Bust] to urls which are not mapped assets
+	BuildVersion string
+	// RelativeURLs strips leading slash from generated urls, e.g. "app.js?v=0a1b2c3d4e" instead of
+	// "/app.js?v=0a1b2c3d4e", for sites hosted from relative location. Absolute urls are kept.
+	RelativeURLs bool
+	// VersionStrategy selects how scanned assets are versioned, look [VersionStrategy]
+	VersionStrategy VersionStrategy
+	// HashLenByKind overrides HashLen for assets of given kind, e.g. shorter hashes for images.
+	// Like HashLen, values must be between 0 and 64, otherwise scans fail with [ErrInvalidHashLen].
+	HashLenByKind map[Kind]int
 	// Left trim subsrtring from final path
 	Trim string
+	// InferExtensions resolves paths without extension to ".js" or ".css" asset, in that order,
+	// e.g. "app" to "app.js"
+	InferExtensions bool
+	// StripScanRoot makes scanned asset paths relative to scanned directory, e.g. scanning "assets"
+	// maps "assets/css/app.css" as "css/app.css". Trim is ignored when set.
+	StripScanRoot bool
+	// DevMode resolves assets against ViteDevServer instead of mapped files
+	DevMode bool
+	// ViteDevServer is the origin of the Vite dev server used in DevMode
+	ViteDevServer string
+	// ReactRefresh adds @react-refresh preamble to ViteClientTag output
+	ReactRefresh bool
+	// NormalizeLineEndings ignores BOM and CRLF line endings when hashing text assets
+	NormalizeLineEndings bool
+	// HashCache stores hashes in sidecar file inside HashCacheDir and reuses them on next scan
+	// for files with unchanged size and modification time. It's used only for directories on
+	// disk, not by [AssetMapper.ScanFS] and [AssetMapper.ScanHTTPFS].
+	HashCache bool
+	// HashCacheDir is directory of HashCache sidecar files, one per scanned directory. It should
+	// be outside of served directories. Empty means "asset-mapper" directory inside
+	// [os.UserCacheDir], or [os.TempDir] if user cache directory is unknown.
+	HashCacheDir string
+	// PostScan is called for every asset created by scan before it's added to Assets.
+	// Returning error aborts the scan.
+	PostScan func(asset *Asset) error
+	// HashIncludesPath mixes asset path into its hash, so files with the same content get different
+	// hashes. Path separators are normalized to "/" before hashing.
+	HashIncludesPath bool
+	// CSSImportHashing mixes hashes of files imported with @import into css file hash,
+	// so importer url changes together with its dependencies
+	CSSImportHashing bool
+	// ContinueOnError makes scan skip files which can't be read and return all their errors
+	// joined after the rest of files is mapped
+	ContinueOnError bool
+	// SkipManifestOutputs excludes files already mapped from manifest, and manifest files itself,
+	// from directory scan, so built files are not mapped twice when public directory is scanned too.
+	// Output files are matched by their path on disk, in [ManifestConfig] Dir, so it works with
+	// any Trim. Manifests should be loaded before scan.
+	SkipManifestOutputs bool
+	// MaxScanDepth limits how many directory levels below scanned root are mapped, e.g. with 1
+	// "app.js" and "js/app.js" are mapped, but "js/vendor/lib.js" is not. Zero means unlimited.
+	MaxScanDepth int
+	// LargeAssetSize is size in bytes above which scanned assets are reported to OnLargeAsset.
+	// Zero disables the check.
+	LargeAssetSize int64
+	// OnLargeAsset is called for every scanned asset bigger than LargeAssetSize, e.g. to log
+	// accidentally committed unoptimized images.
+	OnLargeAsset func(asset *Asset)
+	// Headers are set by [AssetMapper.FileServer] on responses for listed paths. Path is file path
+	// relative to public path or mapped asset path, e.g. "fonts/inter.woff2".
+	Headers map[string]http.Header
+	// KindHeaders are set by [AssetMapper.FileServer] on responses for all files of given kind,
+	// e.g. CORS headers for fonts. Headers for path take precedence.
+	KindHeaders map[Kind]http.Header
+	// RewriteCSSURLs makes [AssetMapper.FileServer] rewrite relative url() references in served css
+	// files to versioned urls of mapped assets. Digest of scanned content doesn't match rewritten
+	// one, so SRI attributes are not added to css links.
+	RewriteCSSURLs bool
+	// CSSMedia maps file name patterns, in [path.Match] syntax, to media attribute of entry css
+	// links. Patterns are matched against base name of css file. Media attribute passed explicitly
+	// to [AssetMapper.CSSLinkTagsFromEntry] takes precedence. Example for print stylesheets:
+	//
+	//	assetMapper.CSSMedia = map[string]string{"print.css": "print", "print-*.css": "print"}
+	CSSMedia map[string]string
+	// Logger receives debug logs about scanned directories, loaded manifests and hash cache usage,
+	// useful to troubleshoot configuration. Nil disables logging.
+	Logger *slog.Logger
+	// MaxManifestSize limits size of loaded manifests and manifest index files in bytes, so
+	// malformed or malicious manifest can't exhaust memory. Bigger manifests fail with
+	// [ErrManifestTooLarge]. Zero means unlimited. [NewAssetMapper] sets [DefaultMaxManifestSize],
+	// so manifests over 32 MiB, which older versions loaded, now fail unless limit is raised.
+	MaxManifestSize int64
+	// DeferByDefault adds defer attribute to scripts rendered by [AssetMapper.ScriptTag] and entry
+	// script helpers. Async and module scripts are skipped, module scripts are deferred anyway.
+	// It can be disabled for single script with "defer" "false" attributes.
+	DeferByDefault bool
+
+	aliases []pathAlias
+	// absolute paths of loaded manifest files and their output files on disk,
+	// look [AssetMapper.SkipManifestOutputs]
+	manifests map[string]bool
+	// paths of assets marked by [AssetMapper.MarkVolatile]
+	volatile map[string]bool
+	// entry scopes lookups, look [AssetMapper.WithEntry]
+	entry string
+	// precomputed entry tags, look [AssetMapper.Precompute]
+	precomputed map[string]entryTags
+	// public path urls were generated with, look [AssetMapper.WithPublicPath]
+	basePublicPath    string
+	rewritePublicPath bool
+	stats             ScanStats
+}
+
+type entryTags struct {
+	css []template.HTML
+	js  []template.HTML
+}
+
+type pathAlias struct {
+	prefix      string
+	replacement string
 }
 
 func NewAssetMapper() *AssetMapper {
@@ -36,6 +256,10 @@ func NewAssetMapper() *AssetMapper {
 		HashLen:    10,
 		Entries:    map[string]*AssetMapperEntry{},
 		Trim:       "",
+
+		ViteDevServer:   "http://localhost:5173",
+		CSSMedia:        map[string]string{},
+		MaxManifestSize: DefaultMaxManifestSize,
 	}
 }
 
@@ -49,13 +273,196 @@ func NewAssetMapper() *AssetMapper {
 //		Type: asset.ViteManifestType,
 //	})
 func (a *AssetMapper) UseManifest(config ManifestConfig) error {
+	file, err := os.Open(config.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	a.addManifestFile(config.Path)
+
+	return a.useManifest(config, file)
+}
+
+// UseManifestBytes loads all assets from manifest content, e.g. generated in memory.
+//
+// Example:
+//
+//	assetMapper.UseManifestBytes(data, asset.ViteManifestType)
+func (a *AssetMapper) UseManifestBytes(data []byte, typ ManifestType) error {
+	return a.useManifest(ManifestConfig{Type: typ}, bytes.NewReader(data))
+}
+
+// UseManifests loads several manifests all-or-nothing. Manifests are parsed into a copy of mapper,
+// which replaces assets and entries only if all of them were loaded, so failed manifest doesn't
+// leave mapper half-populated.
+//
+// Example:
+//
+//	err := assetMapper.UseManifests(
+//		asset.ManifestConfig{Path: "public/.vite/manifest.json", Type: asset.ViteManifestType},
+//		asset.ManifestConfig{Path: "public/bundle/manifest.json", Type: asset.WebpackManifestType},
+//	)
+func (a *AssetMapper) UseManifests(configs ...ManifestConfig) error {
+	staged := a.clone()
+	for _, config := range configs {
+		if err := staged.UseManifest(config); err != nil {
+			return fmt.Errorf("manifest %s: %w", config.Path, err)
+		}
+	}
+
+	a.Assets = staged.Assets
+	a.Entries = staged.Entries
+	a.manifests = staged.manifests
+	a.precomputed = nil
+
+	return nil
+}
+
+// clone returns copy of mapper, which can be changed without affecting original.
+func (a *AssetMapper) clone() *AssetMapper {
+	c := *a
+	c.Assets = maps.Clone(a.Assets)
+	c.Entries = make(map[string]*AssetMapperEntry, len(a.Entries))
+	for name, entry := range a.Entries {
+		copied := *entry
+		copied.CSS = slices.Clone(entry.CSS)
+		copied.JS = slices.Clone(entry.JS)
+		copied.Other = slices.Clone(entry.Other)
+		copied.Assets = slices.Clone(entry.Assets)
+		copied.Prefetch = slices.Clone(entry.Prefetch)
+		copied.Placement = maps.Clone(entry.Placement)
+		c.Entries[name] = &copied
+	}
+	c.aliases = slices.Clone(a.aliases)
+	c.manifests = maps.Clone(a.manifests)
+	c.volatile = maps.Clone(a.volatile)
+	c.precomputed = nil
+
+	return &c
+}
+
+// Snapshot returns copy of asset mapper, which is not affected by later changes of a, like
+// rescanning directories or loading manifests. Snapshot should be treated as read only, so
+// Get and tag helpers can be called on it concurrently without locks.
+//
+// Snapshots can be swapped atomically while original is reloaded in background:
+//
+//	current := atomic.Pointer[asset.AssetMapper]{}
+//	current.Store(assetMapper.Snapshot())
+//
+//	// on change
+//	assetMapper.ScanDirReplace("public")
+//	current.Store(assetMapper.Snapshot())
+//
+//	// in handler
+//	current.Load().Get("app.js")
+func (a *AssetMapper) Snapshot() *AssetMapper {
+	c := a.clone()
+
+	// assets are shared by clone, copy them keeping the same asset under all its keys
+	copies := make(map[*Asset]*Asset, len(c.Assets))
+	for key, asset := range c.Assets {
+		copied, ok := copies[asset]
+		if !ok {
+			copied = new(Asset)
+			*copied = *asset
+			copies[asset] = copied
+		}
+		c.Assets[key] = copied
+	}
+	c.Headers = maps.Clone(a.Headers)
+	c.KindHeaders = maps.Clone(a.KindHeaders)
+	c.HashLenByKind = maps.Clone(a.HashLenByKind)
+	c.CSSMedia = maps.Clone(a.CSSMedia)
+	// precomputed map is replaced, never modified, so it can be shared
+	c.precomputed = a.precomputed
+
+	return c
+}
+
+func (a *AssetMapper) useManifest(config ManifestConfig, r io.Reader) error {
+	a.precomputed = nil
+	assets, entries := len(a.Assets), len(a.Entries)
+
+	r, tooLarge := a.limitManifest(r)
+
+	var err error
 	switch config.Type {
 	case ViteManifestType:
-		return parseViteManifest(config.Path, a)
+		err = parseViteManifest(config, r, a)
 	case WebpackManifestType:
-		return parseWebpackManifest(config.Path, a)
+		err = parseWebpackManifest(config, r, a)
+	case PassthroughManifestType:
+		err = parsePassthroughManifest(config, r, a)
+	case SymfonyManifestType:
+		err = parseSymfonyManifest(config, r, a)
+	default:
+		return ErrUnknownManifestType
+	}
+	if err := tooLarge(); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	a.debug("manifest loaded", "path", config.Path, "type", config.Type,
+		"assets", len(a.Assets)-assets, "entries", len(a.Entries)-entries)
+	return nil
+}
+
+// limitManifest limits r to MaxManifestSize. Returned func reports [ErrManifestTooLarge] after
+// r was read, if manifest exceeded the limit.
+func (a *AssetMapper) limitManifest(r io.Reader) (io.Reader, func() error) {
+	if a.MaxManifestSize <= 0 {
+		return r, func() error { return nil }
+	}
+
+	// one byte over limit is read to detect manifests exceeding it
+	limited := &io.LimitedReader{R: r, N: a.MaxManifestSize + 1}
+	return limited, func() error {
+		if limited.N <= 0 {
+			return fmt.Errorf("%w: limit is %d bytes", ErrManifestTooLarge, a.MaxManifestSize)
+		}
+		return nil
+	}
+}
+
+// debug logs message to Logger, if it's set.
+func (a *AssetMapper) debug(msg string, args ...any) {
+	if a.Logger != nil {
+		a.Logger.Debug(msg, args...)
 	}
-	return errors.New("undefined manifest type")
+}
+
+func (a *AssetMapper) warn(msg string, args ...any) {
+	if a.Logger != nil {
+		a.Logger.Warn(msg, args...)
+	}
+}
+
+// PublicPathPrefix returns PublicPath normalized to URL path with leading and trailing slash,
+// so it can be used to mount file server in the same place where assets are linked.
+// For absolute PublicPath (CDN) only path part is returned.
+//
+// Example:
+//
+//	assetMapper.PublicPath = "static"
+//	prefix := assetMapper.PublicPathPrefix() // "/static/"
+//	http.Handle("GET "+prefix, http.StripPrefix(strings.TrimSuffix(prefix, "/"), http.FileServer(http.Dir("./public"))))
+func (a *AssetMapper) PublicPathPrefix() string {
+	prefix := a.PublicPath
+	if u, err := url.Parse(prefix); err == nil && u.Host != "" {
+		prefix = u.Path
+	}
+
+	prefix = "/" + strings.Trim(prefix, "/") + "/"
+	if prefix == "//" {
+		return "/"
+	}
+
+	return prefix
 }
 
 // CreateEntry creates AssetsMapperEntry if not exists and returns pointer to that entry.
@@ -63,102 +470,893 @@ func (a *AssetMapper) CreateEntry(name string) *AssetMapperEntry {
 	if e, ok := a.Entries[name]; ok {
 		return e
 	}
+	a.precomputed = nil
 
 	a.Entries[name] = &AssetMapperEntry{
 		CSS: []string{},
 		JS:  []string{},
 	}
-
-	return a.Entries[name]
+
+	return a.Entries[name]
+}
+
+// IsEmpty reports whether mapper has no assets and no entries, e.g. because of misconfigured
+// scan directory or manifest path.
+func (a *AssetMapper) IsEmpty() bool {
+	return len(a.Assets) == 0 && len(a.Entries) == 0
+}
+
+// EntryNames returns sorted names of all entries.
+func (a *AssetMapper) EntryNames() []string {
+	names := make([]string, 0, len(a.Entries))
+	for name := range a.Entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Add adds file url to entry CSS or JS list by its extension, ignoring version query. Other files
+// are added to Other list.
+func (entry *AssetMapperEntry) Add(path string) {
+	switch file := fileKey(path); {
+	case isCSS(file):
+		entry.CSS = append(entry.CSS, path)
+	case isJS(file):
+		entry.JS = append(entry.JS, path)
+	default:
+		entry.Other = append(entry.Other, path)
+	}
+}
+
+// AddAsset adds asset to list. If renew is set to true, existing asset will be
+// replaced by provided one. Asset is available by its path and output file path.
+func (a *AssetMapper) AddAsset(asset *Asset, renew bool) {
+	if !renew {
+		if _, ok := a.Assets[asset.Path]; ok {
+			return
+		}
+	}
+
+	a.Assets[asset.Path] = asset
+	if file := fileKey(asset.File); file != "" {
+		a.Assets[file] = asset
+	}
+	// rescanned asset can change urls in entry tags
+	a.precomputed = nil
+}
+
+// ScanDir walks directory and maps all files to AssetMapper, storing its path and hash.
+// Already mapped paths are kept, so when ScanDir is called for overlapping directories,
+// the first scanned file wins. Use [AssetMapper.ScanDirReplace] to prefer the latest one.
+func (a *AssetMapper) ScanDir(dirName string) error {
+	return a.scanDir(dirName, time.Time{}, a.scanKey(dirName), func(asset *Asset) error {
+		a.AddAsset(asset, false)
+		return nil
+	})
+}
+
+// ScanDirReplace walks directory like [AssetMapper.ScanDir], but replaces already mapped assets
+// with the same path, so the last scanned file wins. Like with [AssetMapper.ScanDirSince],
+// assets loaded from manifest are never replaced.
+func (a *AssetMapper) ScanDirReplace(dirName string) error {
+	return a.ScanDirSince(dirName, time.Time{})
+}
+
+// ScanDirSince walks directory like [AssetMapper.ScanDir], but hashes only files modified after since
+// and replaces their existing assets. Other mapped assets are kept, so it can be used for quick rescans
+// in watch mode. Assets loaded from manifest are never replaced, their urls are already fingerprinted.
+func (a *AssetMapper) ScanDirSince(dirName string, since time.Time) error {
+	return a.scanDir(dirName, since, a.scanKey(dirName), func(asset *Asset) error {
+		if existing, ok := a.Assets[asset.Path]; ok && existing.Fingerprinted {
+			return nil
+		}
+		a.AddAsset(asset, true)
+		return nil
+	})
+}
+
+// scanKey returns func converting file path in scanned directory into asset path.
+func (a *AssetMapper) scanKey(dirName string) func(path string) string {
+	if a.StripScanRoot {
+		return func(path string) string {
+			rel, err := filepath.Rel(dirName, path)
+			if err != nil {
+				return path
+			}
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	return func(path string) string {
+		if a.Trim != "" {
+			path = strings.TrimPrefix(path, a.Trim)
+		}
+		return path
+	}
+}
+
+// ScanRoots maps files from several directories into one public namespace. Unlike [AssetMapper.ScanDir]
+// asset paths are relative to their root, so "frontend/dist/app.js" and "legacy/public/old.js" are
+// available as "app.js" and "old.js".
+//
+// If the same relative path exists in several roots, the first root wins and error wrapping
+// [ErrAssetCollision] with list of all collisions is returned after all roots are scanned.
+func (a *AssetMapper) ScanRoots(roots ...string) error {
+	owners := map[string]string{}
+	collisions := []string{}
+
+	for _, root := range roots {
+		err := a.scanDir(root, time.Time{}, func(path string) string {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return path
+			}
+			return filepath.ToSlash(rel)
+		}, func(asset *Asset) error {
+			if owner, ok := owners[asset.Path]; ok {
+				collisions = append(collisions, fmt.Sprintf("%s (%s, %s)", asset.Path, owner, root))
+				return nil
+			}
+			owners[asset.Path] = root
+
+			a.AddAsset(asset, false)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(collisions) > 0 {
+		return fmt.Errorf("%w: %s", ErrAssetCollision, strings.Join(collisions, ", "))
+	}
+
+	return nil
+}
+
+// scanDir walks directory on disk with [AssetMapper.scanFS]. key converts file path on disk,
+// e.g. "public/js/app.js", into asset path.
+func (a *AssetMapper) scanDir(dirName string, since time.Time, key func(path string) string, add func(asset *Asset) error) error {
+	return a.scanFS(os.DirFS(dirName), ".", dirName, since, func(name string) string {
+		return key(filepath.Join(dirName, filepath.FromSlash(name)))
+	}, add)
+}
+
+// scanFS walks root directory of fsys and passes asset created from every file to add func. All
+// scan entry points share it, so they support the same options. key converts file name in fsys
+// into asset path. dir is directory on disk fsys is rooted at, or empty for virtual file systems,
+// which don't support HashCache. Files not modified after since are skipped, unless since is zero.
+func (a *AssetMapper) scanFS(fsys fs.FS, root, dir string, since time.Time, key func(name string) string, add func(asset *Asset) error) error {
+	if err := a.checkHashLen(); err != nil {
+		return err
+	}
+	root = pathpkg.Clean(root)
+
+	var cache *hashCache
+	if a.HashCache && dir != "" {
+		cache = loadHashCache(a.hashCachePath(filepath.Join(dir, filepath.FromSlash(root))), a.hashOptions())
+	}
+
+	files := map[string]*Asset{}
+	names := []string{}
+	fileErrors := []error{}
+	fileError := func(err error) error {
+		err = diskError(dir, err)
+		if !a.ContinueOnError {
+			return err
+		}
+		fileErrors = append(fileErrors, err)
+		return nil
+	}
+
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fileError(err)
+		}
+
+		rel := relName(root, name)
+		if d.IsDir() {
+			if a.MaxScanDepth > 0 && rel != "." && rel != "" && strings.Count(rel, "/")+1 > a.MaxScanDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		// sidecar written inside scan root by older versions is never an asset
+		if rel == hashCacheFile {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fileError(err)
+		}
+		if !since.IsZero() && !info.ModTime().After(since) {
+			// unchanged file stays in sidecar, so next full scan doesn't hash it again
+			if cache != nil {
+				cache.keep(rel, info)
+			}
+			return nil
+		}
+		if a.SkipManifestOutputs && a.manifestOutput(dir, name, key(name)) {
+			return nil
+		}
+
+		asset, assetErr := a.scanFile(fsys, name, rel, key(name), info, cache)
+		if assetErr != nil {
+			return fileError(assetErr)
+		}
+
+		files[name] = asset
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// cache is only an optimization, so failed write doesn't fail the scan
+	if cache != nil {
+		if err := cache.save(); err != nil {
+			a.warn("hash cache not saved", "path", cache.path, "error", err)
+		}
+	}
+
+	if a.CSSImportHashing {
+		if err := foldCSSImports(fsys, files); err != nil {
+			return diskError(dir, err)
+		}
+	}
+
+	for _, name := range names {
+		asset := files[name]
+		a.checkSize(asset)
+		if a.PostScan != nil {
+			if err := a.PostScan(asset); err != nil {
+				return err
+			}
+		}
+
+		if err := add(asset); err != nil {
+			return err
+		}
+		a.stats.Files++
+	}
+	a.stats.Errors += len(fileErrors)
+
+	scanned := root
+	if dir != "" {
+		scanned = filepath.Join(dir, filepath.FromSlash(root))
+	}
+	a.debug("directory scanned", "dir", scanned, "files", len(names), "errors", len(fileErrors))
+	return errors.Join(fileErrors...)
+}
+
+// Stats returns counters of all scans done by asset mapper so far.
+func (a *AssetMapper) Stats() ScanStats {
+	return a.stats
+}
+
+// relName returns file name in fsys relative to walked root directory.
+func relName(root, name string) string {
+	if root == "." {
+		return name
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+}
+
+// diskError replaces file name in fsys with path on disk in scan error, if fsys is rooted at dir.
+func diskError(dir string, err error) error {
+	var pathErr *fs.PathError
+	if dir != "" && errors.As(err, &pathErr) {
+		pathErr.Path = filepath.Join(dir, filepath.FromSlash(pathErr.Path))
+	}
+	return err
+}
+
+// manifestOutput reports whether scanned file is loaded manifest or output file mapped from it.
+// name is file name in fsys rooted at dir.
+func (a *AssetMapper) manifestOutput(dir, name, key string) bool {
+	if asset, ok := a.Assets[key]; ok && asset.Fingerprinted {
+		return true
+	}
+	if dir == "" {
+		return false
+	}
+
+	abs, err := filepath.Abs(filepath.Join(dir, filepath.FromSlash(name)))
+	return err == nil && a.manifests[abs]
+}
+
+// addManifestFile records absolute path of manifest or its output file, look [AssetMapper.manifestOutput].
+func (a *AssetMapper) addManifestFile(path string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	if a.manifests == nil {
+		a.manifests = map[string]bool{}
+	}
+	a.manifests[abs] = true
+}
+
+// manifestFiles records output files of manifest loaded from disk and verifies them, if
+// VerifyFiles is set. Parsers call it with all files listed in manifest.
+func (a *AssetMapper) manifestFiles(config ManifestConfig, files []string) error {
+	if config.Path != "" {
+		dir := config.dir()
+		for _, file := range files {
+			if !isAbsoluteURL(file) {
+				a.addManifestFile(filepath.Join(dir, filepath.FromSlash(fileKey(file))))
+			}
+		}
+	}
+
+	return config.verifyFiles(files)
+}
+
+// scanFile creates asset from file name in fsys, reusing hash from cache if possible. rel is file
+// path relative to scanned directory, used as cache key.
+func (a *AssetMapper) scanFile(fsys fs.FS, name, rel, key string, info fs.FileInfo, cache *hashCache) (*Asset, error) {
+	if a.VersionStrategy == VersionStrategyMtime {
+		return a.mtimeAsset(key, info), nil
+	}
+	if cache != nil {
+		if record, ok := cache.get(rel, info, a.hashLen(key)); ok {
+			a.debug("hash cache hit", "file", rel)
+			a.stats.CacheHits++
+			return &Asset{
+				Path:        key,
+				File:        key,
+				Hash:        record.Hash,
+				PublicPath:  a.PublicPath,
+				ModTime:     info.ModTime(),
+				Size:        info.Size(),
+				ContentType: record.ContentType,
+				Width:       record.Width,
+				Height:      record.Height,
+				digest:      record.Digest,
+			}, nil
+		}
+	}
+
+	if cache != nil {
+		a.debug("hash cache miss", "file", rel)
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	asset, err := a.readAsset(f, key)
+	if err != nil {
+		return nil, err
+	}
+	asset.ModTime = info.ModTime()
+	asset.Size = info.Size()
+	a.stats.HashedFiles++
+	a.stats.HashedBytes += asset.Size
+
+	if cache != nil {
+		cache.set(rel, info, asset)
+	}
+
+	return asset, nil
+}
+
+// checkSize reports asset to OnLargeAsset, if it's bigger than LargeAssetSize.
+func (a *AssetMapper) checkSize(asset *Asset) {
+	if a.LargeAssetSize > 0 && a.OnLargeAsset != nil && asset.Size > a.LargeAssetSize {
+		a.OnLargeAsset(asset)
+	}
+}
+
+// readAsset creates asset from r using mapper hashing options.
+func (a *AssetMapper) readAsset(r io.Reader, path string) (*Asset, error) {
+	salt := ""
+	if a.HashIncludesPath {
+		// separators are normalized, so builds on every platform produce the same hash
+		salt = strings.ReplaceAll(path, "\\", "/")
+	}
+
+	result, err := analyze(r, path, a.NormalizeLineEndings && isText(path), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return analyzedAsset(result, path, a.PublicPath, a.hashLen(path)), nil
+}
+
+// mtimeAsset creates asset versioned with modification time and size of file, look [VersionStrategyMtime].
+func (a *AssetMapper) mtimeAsset(path string, info fs.FileInfo) *Asset {
+	return &Asset{
+		Path:        path,
+		File:        path,
+		Hash:        strconv.FormatInt(info.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(info.Size(), 36),
+		PublicPath:  a.PublicPath,
+		ModTime:     info.ModTime(),
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(path)),
+	}
+}
+
+// hashCachePath returns path of HashCache sidecar file of scanned directory, named after its
+// absolute path, so directories sharing HashCacheDir don't overwrite each other's records.
+func (a *AssetMapper) hashCachePath(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	sum := sha256.Sum256([]byte(dir))
+
+	cacheDir := a.HashCacheDir
+	if cacheDir == "" {
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			userDir = os.TempDir()
+		}
+		cacheDir = filepath.Join(userDir, "asset-mapper")
+	}
+
+	return filepath.Join(cacheDir, "asset-hashes-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// hashOptions returns options changing content hashes, so hash cache is invalidated when they change.
+func (a *AssetMapper) hashOptions() string {
+	return fmt.Sprintf("includePath=%t normalizeLineEndings=%t", a.HashIncludesPath, a.NormalizeLineEndings)
+}
+
+// checkHashLen returns error if HashLen or any of HashLenByKind is longer than sha256 hex
+// digest or negative, so scan fails before slicing hashes.
+func (a *AssetMapper) checkHashLen() error {
+	if a.HashLen < 0 || a.HashLen > sha256.Size*2 {
+		return fmt.Errorf("%w: HashLen is %d", ErrInvalidHashLen, a.HashLen)
+	}
+	for _, kind := range slices.Sorted(maps.Keys(a.HashLenByKind)) {
+		if n := a.HashLenByKind[kind]; n < 0 || n > sha256.Size*2 {
+			return fmt.Errorf("%w: HashLenByKind[%s] is %d", ErrInvalidHashLen, kind, n)
+		}
+	}
+	return nil
+}
+
+// hashLen returns hash length for asset path, HashLenByKind takes precedence over HashLen.
+func (a *AssetMapper) hashLen(path string) int {
+	if n, ok := a.HashLenByKind[kindOf(path)]; ok {
+		return n
+	}
+	return a.HashLen
+}
+
+// uniqueAssets returns mapped assets sorted by path. Assets map stores the same asset
+// under several keys, so duplicates are skipped.
+func (a *AssetMapper) uniqueAssets() []*Asset {
+	seen := map[*Asset]bool{}
+	result := []*Asset{}
+
+	for _, asset := range a.Assets {
+		if seen[asset] {
+			continue
+		}
+		seen[asset] = true
+		result = append(result, asset)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
+
+// BuildID returns stable identifier of current asset set, derived from paths and versioned urls of all
+// assets. It changes whenever any asset is added, removed or changed, so it can be used as deploy
+// identifier or single cache busting version.
+func (a *AssetMapper) BuildID() string {
+	hasher := sha256.New()
+	for _, asset := range a.uniqueAssets() {
+		hasher.Write([]byte(asset.Path + "\x00" + asset.String() + "\n"))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:16]
+}
+
+// EntryHash returns stable hash of entry file urls, which include asset versions, so it changes
+// whenever any entry asset changes. Useful as single cache key per page. Empty for unknown entry.
+func (a *AssetMapper) EntryHash(name string) string {
+	entry, ok := a.Entries[name]
+	if !ok {
+		return ""
+	}
+
+	hasher := sha256.New()
+	for _, u := range slices.Concat(entry.CSS, entry.JS, entry.Other) {
+		hasher.Write([]byte(u + "\n"))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:16]
+}
+
+// CheckURLCollisions returns error wrapping [ErrURLCollision] listing public urls shared by assets
+// with different paths. Scanned assets keep their path in url, so collisions usually come from
+// manifests with too short hashes in file names, e.g. two chunks built as "app-a.js".
+func (a *AssetMapper) CheckURLCollisions() error {
+	owners := map[string]string{}
+	collisions := []string{}
+
+	for _, asset := range a.uniqueAssets() {
+		u := asset.String()
+		if owner, ok := owners[u]; ok && owner != asset.Path {
+			collisions = append(collisions, fmt.Sprintf("%s (%s, %s)", u, owner, asset.Path))
+			continue
+		}
+		owners[u] = asset.Path
+	}
+
+	if len(collisions) > 0 {
+		return fmt.Errorf("%w, consider increasing hash length: %s", ErrURLCollision, strings.Join(collisions, ", "))
+	}
+	return nil
+}
+
+// UnusedAssets returns assets which are not referenced by any entry CSS or JS list.
+// Result is sorted by asset path.
+func (a *AssetMapper) UnusedAssets() []*Asset {
+	used := map[string]bool{}
+	for _, entry := range a.Entries {
+		for _, css := range entry.CSS {
+			used[css] = true
+		}
+		for _, js := range entry.JS {
+			used[js] = true
+		}
+		for _, other := range entry.Other {
+			used[other] = true
+		}
+	}
+
+	result := []*Asset{}
+	for _, asset := range a.uniqueAssets() {
+		if used[asset.String()] {
+			continue
+		}
+		result = append(result, asset)
+	}
+
+	return result
+}
+
+// AddPathAlias registers path prefix replacement used by Get when there is no exact match,
+// the same way bundlers resolve import aliases. Aliases are tried in order of registration.
+//
+// Example:
+//
+//	assetMapper.AddPathAlias("@/", "src/")
+//	assetMapper.Get("@/logo.png") // resolves "src/logo.png"
+func (a *AssetMapper) AddPathAlias(prefix, replacement string) {
+	a.aliases = append(a.aliases, pathAlias{prefix: prefix, replacement: replacement})
+}
+
+// WithEntry returns copy of mapper which resolves assets relative to entry source directory first,
+// so pages of the entry can reference its assets by short name. Copy shares assets and entries
+// with original mapper.
+//
+// Example:
+//
+//	// entry "admin" loaded from manifest record with src "src/admin/main.js"
+//	t.Funcs(template.FuncMap{"asset": assetMapper.WithEntry("admin").Get})
+//
+//	<!-- in admin templates resolves "src/admin/logo.png" before "logo.png" -->
+//	<img src="{{ asset "logo.png" }}">
+func (a *AssetMapper) WithEntry(name string) *AssetMapper {
+	scoped := *a
+	scoped.entry = name
+	return &scoped
+}
+
+// lookup finds asset by path, trying path aliases when there is no exact match.
+func (a *AssetMapper) lookup(path string) (*Asset, bool) {
+	path = strings.TrimLeft(path, "/")
+	if entry, ok := a.Entries[a.entry]; ok && a.entry != "" && entry.Dir != "" {
+		if asset, ok := a.Assets[pathpkg.Join(entry.Dir, path)]; ok {
+			return asset, true
+		}
+	}
+	if asset, ok := a.Assets[path]; ok {
+		return asset, true
+	}
+
+	for _, alias := range a.aliases {
+		if rest, ok := strings.CutPrefix(path, alias.prefix); ok {
+			if asset, ok := a.Assets[alias.replacement+rest]; ok {
+				return asset, true
+			}
+		}
+	}
+
+	// stylesheet sources are resolved to compiled css next to them
+	for _, ext := range []string{".scss", ".sass", ".less"} {
+		if base, ok := strings.CutSuffix(path, ext); ok {
+			return a.lookup(base + ".css")
+		}
+	}
+
+	if a.InferExtensions && pathpkg.Ext(path) == "" {
+		for _, ext := range []string{".js", ".css"} {
+			if asset, ok := a.lookup(path + ext); ok {
+				return asset, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Get returns asset url including version. If asset not found returns path param as is.
+// Scanned assets are versioned with "?v=" query, while assets loaded from manifest already have
+// fingerprinted file names and their urls are returned without it.
+// Query string and fragment of path are appended to asset url, e.g. "app.js?nocache=1"
+// results in "/app.js?v=0a1b2c3d4e&nocache=1".
+// Version query of path is replaced by current asset version, so Get is idempotent for already
+// versioned paths like "app.js?v=0a1b2c3d4e" and stale versions are not kept. Urls including
+// PublicPath, like "/static/app.js?v=0a1b2c3d4e", are resolved the same way.
+// Paths containing ".." segments are rejected and empty string is returned.
+func (a *AssetMapper) Get(path string) string {
+	if isTraversal(path) {
+		return ""
+	}
+	if a.DevMode {
+		return a.devURL(path)
+	}
+	if asset, ok := a.lookup(path); ok {
+		return a.assetURL(asset)
+	}
+
+	// query and fragment of mapped asset path are kept, merged with version query
+	if file, suffix, ok := cutQuery(path); ok {
+		if asset, ok := a.lookup(file); ok {
+			u := a.assetURL(asset)
+			if strings.Contains(u, "?v=") {
+				suffix = withoutVersion(suffix)
+			}
+			if query, found := strings.CutPrefix(suffix, "?"); found && strings.Contains(u, "?") {
+				suffix = "&" + query
+			}
+			return u + suffix
+		}
+	}
+
+	// urls already returned by Get are resolved again without public path
+	if rest, ok := a.cutPublicPath(path); ok {
+		if file, _, _ := cutQuery(rest); file != "" {
+			if _, ok := a.lookup(file); ok {
+				return a.Get(rest)
+			}
+		}
+	}
+	return strings.TrimLeft(path, "/")
+}
+
+// cutPublicPath removes PublicPath from url generated by asset mapper. ok is false if url doesn't
+// start with it or PublicPath is root.
+func (a *AssetMapper) cutPublicPath(link string) (string, bool) {
+	if u, err := url.Parse(a.PublicPath); err == nil && u.Host != "" {
+		return strings.CutPrefix(link, strings.TrimSuffix(a.PublicPath, "/")+"/")
+	}
+
+	prefix := strings.TrimLeft(a.PublicPathPrefix(), "/")
+	if prefix == "" {
+		return "", false
+	}
+	return strings.CutPrefix(strings.TrimLeft(link, "/"), prefix)
+}
+
+// GetMany returns map of paths to their urls resolved by [AssetMapper.Get], e.g. to pass set of
+// asset urls to client code.
+func (a *AssetMapper) GetMany(paths ...string) map[string]string {
+	urls := make(map[string]string, len(paths))
+	for _, path := range paths {
+		urls[path] = a.Get(path)
+	}
+	return urls
+}
+
+// AssetsJSON returns urls of paths from [AssetMapper.GetMany] as JSON object, safe to use
+// inside of <script> element.
+//
+// Example usage in template:
+//
+//	<script>window.ASSETS = {{ assetsJson "logo.svg" "worker.js" }};</script>
+func (a *AssetMapper) AssetsJSON(paths ...string) (template.JS, error) {
+	// json.Marshal escapes <, > and &, so content can't close script element
+	content, err := json.Marshal(a.GetMany(paths...))
+	if err != nil {
+		return "", err
+	}
+	return template.JS(content), nil
+}
+
+// assetURL returns public url of asset, volatile assets get unique version on every call.
+func (a *AssetMapper) assetURL(asset *Asset) string {
+	if !a.volatile[asset.Path] {
+		return a.publicURL(asset.String())
+	}
+
+	u, _, _ := strings.Cut(asset.String(), "?")
+	token := strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.FormatUint(volatileCounter.Add(1), 36)
+	return a.publicURL(u + "?v=" + token)
+}
+
+// volatileCounter makes volatile versions unique within the same clock tick.
+var volatileCounter atomic.Uint64
+
+// MarkVolatile marks asset which must not be cached, e.g. runtime config script. Its url gets
+// unique version on every [AssetMapper.Get] call instead of stable hash, and [AssetMapper.FileServer]
+// serves it with "Cache-Control: no-cache". Path is asset path, the same as passed to Get.
+func (a *AssetMapper) MarkVolatile(path string) {
+	if asset, ok := a.lookup(path); ok {
+		path = asset.Path
+	}
+	if a.volatile == nil {
+		a.volatile = map[string]bool{}
+	}
+	a.volatile[strings.TrimLeft(path, "/")] = true
+	a.precomputed = nil
+}
+
+// Bust appends BuildVersion as "v" query param to any url, e.g. versioned API endpoint, merging it
+// with existing query. Url is returned unchanged if BuildVersion is empty.
+//
+// Example usage in template:
+//
+//	<script>fetch("{{ bust "/api/config" }}")</script>
+//
+// Result with BuildVersion "2024.05.1":
+//
+//	<script>fetch("/api/config?v=2024.05.1")</script>
+func (a *AssetMapper) Bust(u string) string {
+	if a.BuildVersion == "" {
+		return u
+	}
+
+	u, fragment, hasFragment := strings.Cut(u, "#")
+	separator := "?"
+	if strings.Contains(u, "?") {
+		separator = "&"
+	}
+	u += separator + "v=" + url.QueryEscape(a.BuildVersion)
+	if hasFragment {
+		u += "#" + fragment
+	}
+	return u
 }
 
-func (entry *AssetMapperEntry) Add(path string) {
-	switch {
-	case isCSS(path):
-		entry.CSS = append(entry.CSS, path)
-	case isJS(path):
-		entry.JS = append(entry.JS, path)
+// resolve returns asset url same as Get, but reports rejected paths as error for tag helpers.
+func (a *AssetMapper) resolve(path string) (string, error) {
+	if isTraversal(path) {
+		return "", fmt.Errorf("%w: %s", ErrPathTraversal, path)
 	}
+	return a.Get(path), nil
 }
 
-// AddAsset adds asset to list. If renew is set to true, existing asset will be
-// replaced by provided one.
-func (a *AssetMapper) AddAsset(asset *Asset, renew bool) {
-	if !renew {
-		if _, ok := a.Assets[asset.Path]; ok {
-			return
-		}
+// WithPublicPath returns copy of mapper generating urls with prefix instead of PublicPath,
+// useful when the same assets are served under per request prefix (e.g. "/{tenant}/static/").
+// Copy shares assets and entries with original mapper.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		tenantAssets := assetMapper.WithPublicPath("/" + r.PathValue("tenant") + "/static/")
+//		templates.ExecuteTemplate(w, "index", map[string]any{"Assets": tenantAssets})
+//	}
+//
+//	<!-- in template -->
+//	{{ .Assets.LinkTag "css/app.css" }}
+func (a *AssetMapper) WithPublicPath(prefix string) *AssetMapper {
+	scoped := *a
+	if !a.rewritePublicPath {
+		scoped.basePublicPath = a.PublicPath
+		scoped.rewritePublicPath = true
 	}
-
-	a.Assets[asset.Path] = asset
-	a.Assets[asset.PublicPath] = asset
+	scoped.PublicPath = prefix
+	scoped.precomputed = nil
+	return &scoped
 }
 
-// ScanDir walks directory and maps all files to AssetMapper, storing its path and hash.
-func (a *AssetMapper) ScanDir(dirName string) error {
-	err := filepath.Walk(dirName, func(path string, info fs.FileInfo, err error) error {
-		if info.IsDir() {
-			return nil
-		}
-
-		f, e := os.Open(path)
-
-		if e != nil {
-			return e
-		}
-
-		if a.Trim != "" {
-			path = strings.TrimPrefix(path, a.Trim)
-		}
-
-		asset, assetErr := NewAsset(f, path, a.PublicPath, a.HashLen)
-		if assetErr != nil {
-			return assetErr
+// publicURL replaces original public path of url with the one set by [AssetMapper.WithPublicPath].
+func (a *AssetMapper) publicURL(u string) string {
+	if a.rewritePublicPath {
+		if rest, ok := strings.CutPrefix(u, strings.TrimRight(a.basePublicPath, "/")); ok {
+			u = joinPath(a.PublicPath, rest)
 		}
+	}
+	if a.RelativeURLs && strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+		u = u[1:]
+	}
+	return u
+}
 
-		a.AddAsset(asset, false)
+func (a *AssetMapper) publicURLs(urls []string) []string {
+	if !a.rewritePublicPath && !a.RelativeURLs {
+		return urls
+	}
 
-		return nil
-	})
+	result := make([]string, 0, len(urls))
+	for _, u := range urls {
+		result = append(result, a.publicURL(u))
+	}
+	return result
+}
 
-	return err
+// booleanAttributes are rendered without value
+var booleanAttributes = map[string]bool{
+	"async":    true,
+	"defer":    true,
+	"disabled": true,
 }
 
-func extractAssetPathFromMap(m map[string]*Asset, search string) string {
-	search = strings.TrimLeft(search, "/")
-	if asset, ok := m[search]; ok {
-		return asset.String()
+// attributes renders attributes with quote style configured by SingleQuoteAttributes.
+func (a *AssetMapper) attributes(m map[string]string) string {
+	if a.SingleQuoteAttributes {
+		return renderAttributes(m, '\'')
 	}
-	return search
+	return renderAttributes(m, '"')
 }
 
-// Get returns asset url including version. If asset not found returns path param as is.
-func (a *AssetMapper) Get(path string) string {
-	return extractAssetPathFromMap(a.Assets, path)
-}
+// renderAttributes renders attributes sorted by name with values wrapped in quote. Values are
+// html escaped, which covers both quote characters.
+func renderAttributes(m map[string]string, quote byte) string {
+	var buf [8]string
+	keys := buf[:0]
+	size := 0
+	for k, v := range m {
+		keys = append(keys, k)
+		size += len(k) + len(v) + 4
+	}
+	slices.Sort(keys)
 
-func attributeMapToString(m map[string]string) string {
-	s := []string{}
+	var b strings.Builder
+	b.Grow(size)
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(escapeAttribute(k))
 
-	for k, v := range m {
-		if k == "async" || k == "defer" {
-			s = append(s, k)
+		if booleanAttributes[k] {
 			continue
 		}
-		s = append(s, fmt.Sprintf(`%s="%s"`, html.EscapeString(k), html.EscapeString(v)))
+		b.WriteByte('=')
+		b.WriteByte(quote)
+		b.WriteString(escapeAttribute(m[k]))
+		b.WriteByte(quote)
 	}
 
-	return strings.Join(s, " ")
+	return b.String()
+}
+
+// escapeAttribute skips html.EscapeString for common case of nothing to escape.
+func escapeAttribute(s string) string {
+	if !strings.ContainsAny(s, `<>&'"`) {
+		return s
+	}
+	return html.EscapeString(s)
 }
 
 func tagAttributes(attrs []string) (map[string]string, error) {
 	attrMap := map[string]string{}
 
 	if len(attrs)%2 != 0 {
-		return nil, errors.New("attrs must be an even number of strings")
+		return nil, ErrOddAttributes
 	}
 
 	for i := 0; i < len(attrs); i += 2 {
+		// values are escaped, but names can't be, so names breaking the tag are rejected
+		if attrs[i] == "" || strings.ContainsAny(attrs[i], " \t\n\f\r\"'<>/=") {
+			return nil, fmt.Errorf("invalid attribute name %q", attrs[i])
+		}
+		if attrs[i] == "fetchpriority" && !slices.Contains([]string{"high", "low", "auto"}, attrs[i+1]) {
+			return nil, fmt.Errorf("%w, got %q", ErrInvalidFetchPriority, attrs[i+1])
+		}
 		attrMap[attrs[i]] = attrs[i+1]
 	}
 
@@ -185,27 +1383,89 @@ func scriptTag(attrs string) template.HTML {
 //	{{ scriptTag "defered.js" "defer" "" }}
 //	{{ scriptTag "some-async.js" "async" "" }}
 //
+//	<!-- Example append NoScript fallback -->
+//	{{ scriptTag "app.js" "noscript" "true" }}
+//
 // Result:
 //
 //	<script src="main.js"></script>
 //
 //	<!-- Passing additional attributes to script tag -->
-//	<script src="other.js" type="module" id="other-script"></script>
+//	<script id="other-script" src="other.js" type="module"></script>
 //
 //	<!-- Example set defer or async attributes -->
 //	<script defer src="defered.js"></script>
 //	<script async src="some-async.js"></script>
+//
+//	<!-- Example append NoScript fallback -->
+//	<script src="app.js"></script><noscript>...</noscript>
 func (a *AssetMapper) ScriptTag(path string, attrs ...string) (template.HTML, error) {
-	link := a.Get(path)
+	link, err := a.resolve(path)
+	if err != nil {
+		return "", err
+	}
 
 	attrMap, err := tagAttributes(attrs)
 	if err != nil {
 		return "", err
 	}
 
+	noScript := a.noScript(attrMap)
 	attrMap["src"] = link
+	if _, ok := attrMap["type"]; !ok && a.DevMode {
+		attrMap["type"] = "module"
+	}
+	a.setDefer(attrMap)
+	a.setIntegrity(attrMap, path, link)
+
+	return scriptTag(a.attributes(attrMap)) + noScript, nil
+}
+
+// setDefer adds defer attribute to script attributes, if DeferByDefault is set. Explicit "defer"
+// attribute is kept, except "false" value which removes it. Without DeferByDefault attributes
+// are kept as passed.
+func (a *AssetMapper) setDefer(attrMap map[string]string) {
+	if !a.DeferByDefault {
+		return
+	}
+	if value, ok := attrMap["defer"]; ok {
+		if value == "false" {
+			delete(attrMap, "defer")
+		}
+		return
+	}
 
-	return scriptTag(attributeMapToString(attrMap)), nil
+	if _, async := attrMap["async"]; !async && attrMap["type"] != "module" {
+		attrMap["defer"] = ""
+	}
+}
+
+// NoScriptTag returns NoScript fallback wrapped in noscript tag, or empty string if not set.
+// It's rendered once per page, e.g. after last script.
+//
+// Example usage in template:
+//
+//	{{ entryBodyScripts "app" }}
+//	{{ noScriptTag }}
+func (a *AssetMapper) NoScriptTag() template.HTML {
+	if a.NoScript == "" {
+		return ""
+	}
+	return "<noscript>" + a.NoScript + "</noscript>"
+}
+
+// noScript removes "noscript" option from script attributes and returns NoScript fallback
+// wrapped in noscript tag, if option is set to anything but "false".
+func (a *AssetMapper) noScript(attrMap map[string]string) template.HTML {
+	value, ok := attrMap["noscript"]
+	if !ok {
+		return ""
+	}
+	delete(attrMap, "noscript")
+	if value == "false" {
+		return ""
+	}
+	return a.NoScriptTag()
 }
 
 func linkTag(attrs string) template.HTML {
@@ -224,13 +1484,21 @@ func linkTag(attrs string) template.HTML {
 //	<!-- Passing additional attributes to link tag -->
 //	{{ linkTag "homepage.css" "id" "homepage-css" "media" "screen" }}
 //
+//	<!-- Alternate stylesheet, disabled is rendered as boolean attribute -->
+//	{{ linkTag "dark.css" "rel" "alternate stylesheet" "title" "Dark" "disabled" "" }}
+//
 // Result:
 //
 //	<link href="style.css" rel="stylesheet"/>
 //	<!-- Passing additional attributes to link tag -->
-//	<link href="homepage.css" rel="stylesheet" id="homepage-css" media="screen"/>
+//	<link href="homepage.css" id="homepage-css" media="screen" rel="stylesheet"/>
+//	<!-- Alternate stylesheet, disabled is rendered as boolean attribute -->
+//	<link disabled href="dark.css" rel="alternate stylesheet" title="Dark"/>
 func (a *AssetMapper) LinkTag(path string, attrs ...string) (template.HTML, error) {
-	link := a.Get(path)
+	link, err := a.resolve(path)
+	if err != nil {
+		return "", err
+	}
 
 	attrs = append([]string{"rel", "stylesheet"}, attrs...)
 	attrMap, err := tagAttributes(attrs)
@@ -239,14 +1507,214 @@ func (a *AssetMapper) LinkTag(path string, attrs ...string) (template.HTML, erro
 	}
 
 	attrMap["href"] = link
+	a.setIntegrity(attrMap, path, link)
+
+	return linkTag(a.attributes(attrMap)), nil
+}
+
+// setIntegrity adds integrity and crossorigin attributes for asset at path according to Integrity mode.
+func (a *AssetMapper) setIntegrity(attrMap map[string]string, path, link string) {
+	if a.Integrity == IntegrityOff || a.DevMode {
+		return
+	}
+	if _, ok := attrMap["integrity"]; ok {
+		return
+	}
+
+	asset, ok := a.lookup(path)
+	if !ok || asset.digest == nil || a.rewritten(asset) {
+		return
+	}
+	if a.Integrity == IntegrityCrossOrigin && !a.crossOrigin(link) {
+		return
+	}
+
+	attrMap["integrity"] = "sha256-" + base64.StdEncoding.EncodeToString(asset.digest)
+	if _, ok := attrMap["crossorigin"]; !ok {
+		attrMap["crossorigin"] = "anonymous"
+	}
+}
+
+// rewritten reports whether asset is served with content rewritten by [AssetMapper.FileServer],
+// so digest of scanned file doesn't match served body.
+func (a *AssetMapper) rewritten(asset *Asset) bool {
+	return a.RewriteCSSURLs && isCSS(asset.File)
+}
+
+// crossOrigin reports whether url is absolute and points to origin other than Origin.
+func (a *AssetMapper) crossOrigin(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if a.Origin == "" {
+		return true
+	}
+
+	origin, err := url.Parse(a.Origin)
+	return err != nil || origin.Host != u.Host || (u.Scheme != "" && origin.Scheme != u.Scheme)
+}
+
+// IconTag returns HTML link tag for favicon. Sizes attribute is omitted when sizes is empty.
+// attrs param works the same way as in [AssetMapper.LinkTag].
+//
+// Example usage in template:
+//
+//	{{ iconTag "favicon-32x32.png" "32x32" }}
+//
+// Result:
+//
+//	<link href="/favicon-32x32.png?v=0a1b2c3d4e" rel="icon" sizes="32x32"/>
+func (a *AssetMapper) IconTag(path, sizes string, attrs ...string) (template.HTML, error) {
+	return a.iconTag("icon", path, sizes, attrs)
+}
+
+// AppleTouchIconTag returns HTML link tag with rel="apple-touch-icon".
+// For more information look [AssetMapper.IconTag] method
+func (a *AssetMapper) AppleTouchIconTag(path, sizes string, attrs ...string) (template.HTML, error) {
+	return a.iconTag("apple-touch-icon", path, sizes, attrs)
+}
+
+// WebManifestTag returns HTML link tag for PWA web app manifest. attrs param works the same way
+// as in [AssetMapper.LinkTag], e.g. "crossorigin" "use-credentials" for manifests behind auth.
+//
+// Example usage in template:
+//
+//	{{ webManifestTag "manifest.webmanifest" }}
+//
+// Result:
+//
+//	<link href="/manifest.webmanifest?v=0a1b2c3d4e" rel="manifest"/>
+func (a *AssetMapper) WebManifestTag(path string, attrs ...string) (template.HTML, error) {
+	return a.iconTag("manifest", path, "", attrs)
+}
+
+func (a *AssetMapper) iconTag(rel, path, sizes string, attrs []string) (template.HTML, error) {
+	attrs = append([]string{"rel", rel}, attrs...)
+	if sizes != "" {
+		attrs = append(attrs, "sizes", sizes)
+	}
+
+	attrMap, err := tagAttributes(attrs)
+	if err != nil {
+		return "", err
+	}
+
+	attrMap["href"], err = a.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	return linkTag(a.attributes(attrMap)), nil
+}
 
-	return linkTag(attributeMapToString(attrMap)), nil
+// ImgTag returns HTML img tag. attrs param works the same way as in [AssetMapper.LinkTag].
+//
+// Example usage in template:
+//
+//	{{ imgTag "hero.webp" "alt" "Hero" "fetchpriority" "high" }}
+//
+// Result:
+//
+//	<img alt="Hero" fetchpriority="high" src="/hero.webp?v=0a1b2c3d4e"/>
+func (a *AssetMapper) ImgTag(path string, attrs ...string) (template.HTML, error) {
+	attrMap, err := tagAttributes(attrs)
+	if err != nil {
+		return "", err
+	}
+
+	attrMap["src"], err = a.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(fmt.Sprintf("<img %s/>", a.attributes(attrMap))), nil
+}
+
+// PreloadTag returns HTML link tag with rel="preload". The "as" attribute is inferred from file
+// extension (style, script, image, font, or fetch for json data) unless passed in attrs. Fonts and
+// fetched data get crossorigin attribute, because browsers request them in cors mode and would
+// ignore preload without it.
+//
+// Example usage in template:
+//
+//	{{ preloadTag "hero.webp" "fetchpriority" "high" }}
+//
+// Result:
+//
+//	<link as="image" fetchpriority="high" href="/hero.webp?v=0a1b2c3d4e" rel="preload"/>
+func (a *AssetMapper) PreloadTag(path string, attrs ...string) (template.HTML, error) {
+	attrs = append([]string{"rel", "preload"}, attrs...)
+	attrMap, err := tagAttributes(attrs)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := attrMap["as"]; !ok {
+		switch kindOf(path) {
+		case KindCSS:
+			attrMap["as"] = "style"
+		case KindJS:
+			attrMap["as"] = "script"
+		case KindImage:
+			attrMap["as"] = "image"
+		case KindFont:
+			attrMap["as"] = "font"
+		default:
+			if isData(fileKey(path)) {
+				attrMap["as"] = "fetch"
+			}
+		}
+	}
+	if _, ok := attrMap["crossorigin"]; !ok && (attrMap["as"] == "font" || attrMap["as"] == "fetch") {
+		attrMap["crossorigin"] = ""
+	}
+
+	attrMap["href"], err = a.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	return linkTag(a.attributes(attrMap)), nil
+}
+
+// PreconnectTag returns preconnect and dns-prefetch link tags for origin of absolute PublicPath (CDN),
+// so browser can open connection before assets are requested. For relative PublicPath result is empty.
+//
+// Example usage in template:
+//
+//	{{ preconnectTag }}
+//
+// Result for PublicPath "https://cdn.example.com/static/":
+//
+//	<link href="https://cdn.example.com" rel="preconnect"/>
+//	<link href="https://cdn.example.com" rel="dns-prefetch"/>
+func (a *AssetMapper) PreconnectTag() (template.HTML, error) {
+	u, err := url.Parse(a.PublicPath)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", nil
+	}
+
+	origin := u.Scheme + "://" + u.Host
+	if u.Scheme == "" {
+		origin = "//" + u.Host
+	}
+
+	tags := []string{}
+	for _, rel := range []string{"preconnect", "dns-prefetch"} {
+		tags = append(tags, string(linkTag(a.attributes(map[string]string{"href": origin, "rel": rel}))))
+	}
+
+	return template.HTML(strings.Join(tags, "\n")), nil
 }
 
 // CSSEntry returns slice of css urls from entrypoint
 func (a *AssetMapper) CSSEntry(name string) []string {
 	if s, ok := a.Entries[name]; ok {
-		return s.CSS
+		return a.publicURLs(s.CSS)
 	}
 	return nil
 }
@@ -254,7 +1722,15 @@ func (a *AssetMapper) CSSEntry(name string) []string {
 // JSEntry returns slice of js urls from entrypoint
 func (a *AssetMapper) JSEntry(name string) []string {
 	if s, ok := a.Entries[name]; ok {
-		return s.JS
+		return a.publicURLs(s.JS)
+	}
+	return nil
+}
+
+// OtherEntry returns slice of urls of entry files which are neither css nor js, e.g. wasm modules
+func (a *AssetMapper) OtherEntry(name string) []string {
+	if s, ok := a.Entries[name]; ok {
+		return a.publicURLs(s.Other)
 	}
 	return nil
 }
@@ -263,35 +1739,200 @@ func (a *AssetMapper) JSEntry(name string) []string {
 //
 // For more information look [AssetMapper.LinkTag] method
 func (a *AssetMapper) CSSLinkTagsFromEntry(name string, attrs ...string) ([]template.HTML, error) {
-	attrs = append([]string{"rel", "stylesheet"}, attrs...)
+	if tags, ok := a.precomputed[name]; ok && len(attrs) == 0 {
+		return tags.css, nil
+	}
+	return a.cssLinkTags(name, attrs)
+}
+
+func (a *AssetMapper) cssLinkTags(name string, attrs []string) ([]template.HTML, error) {
+	attrs = append([]string{"rel", "stylesheet"}, a.entryAttrs(attrs)...)
 	attrMap, err := tagAttributes(attrs)
 	if err != nil {
 		return nil, err
 	}
 
+	_, explicitMedia := attrMap["media"]
+
 	result := []template.HTML{}
 	for _, css := range a.CSSEntry(name) {
 		attrMap["href"] = css
-		result = append(result, linkTag(attributeMapToString(attrMap)))
+		if !explicitMedia {
+			delete(attrMap, "media")
+			if media := a.cssMedia(css); media != "" {
+				attrMap["media"] = media
+			}
+		}
+		result = append(result, linkTag(a.attributes(attrMap)))
 	}
 
 	return result, nil
 }
 
+// cssMedia returns media of css url from first matching CSSMedia pattern, sorted by pattern
+// for stable results.
+func (a *AssetMapper) cssMedia(cssURL string) string {
+	file, _, _ := cutQuery(cssURL)
+	name := pathpkg.Base(file)
+	for _, pattern := range slices.Sorted(maps.Keys(a.CSSMedia)) {
+		if ok, _ := pathpkg.Match(pattern, name); ok {
+			return a.CSSMedia[pattern]
+		}
+	}
+	return ""
+}
+
 // JSScriptTagsFromEntry return slice of html scripts from entry.
 //
 // For more information look [AssetMapper.ScriptTag] method
 func (a *AssetMapper) JSScriptTagsFromEntry(name string, attrs ...string) ([]template.HTML, error) {
+	if tags, ok := a.precomputed[name]; ok && len(attrs) == 0 {
+		return tags.js, nil
+	}
+	return a.jsScriptTags(name, attrs)
+}
+
+func (a *AssetMapper) jsScriptTags(name string, attrs []string) ([]template.HTML, error) {
+	return a.scriptTags(a.JSEntry(name), a.entryAttrs(attrs))
+}
+
+// entryAttrs returns attrs of entry tags with data-turbo-track added, if TurboTrack is set.
+// Passed attrs take precedence.
+func (a *AssetMapper) entryAttrs(attrs []string) []string {
+	if !a.TurboTrack {
+		return attrs
+	}
+	return append([]string{"data-turbo-track", "reload"}, attrs...)
+}
+
+func (a *AssetMapper) scriptTags(urls []string, attrs []string) ([]template.HTML, error) {
 	attrMap, err := tagAttributes(attrs)
 	if err != nil {
 		return nil, err
 	}
+	noScript := a.noScript(attrMap)
+	a.setDefer(attrMap)
 
 	result := []template.HTML{}
-	for _, js := range a.JSEntry(name) {
+	for _, js := range urls {
 		attrMap["src"] = js
-		result = append(result, scriptTag(attributeMapToString(attrMap)))
+		result = append(result, scriptTag(a.attributes(attrMap)))
+	}
+	// fallback follows the last script, once per call
+	if noScript != "" && len(result) > 0 {
+		result = append(result, noScript)
 	}
 
 	return result, nil
 }
+
+// EntryHTML returns all entry tags as single HTML: css links followed by js scripts,
+// separated by new line.
+//
+// Example usage in template:
+//
+//	{{ entryHTML "app" }}
+//
+// Result:
+//
+//	<link href="/assets/app-o2N34dPp.css" rel="stylesheet"/>
+//	<script src="/assets/app-CKgRTByK.js"></script>
+func (a *AssetMapper) EntryHTML(name string) (template.HTML, error) {
+	links, err := a.CSSLinkTagsFromEntry(name)
+	if err != nil {
+		return "", err
+	}
+
+	scripts, err := a.JSScriptTagsFromEntry(name)
+	if err != nil {
+		return "", err
+	}
+
+	tags := []string{}
+	for _, tag := range append(links, scripts...) {
+		tags = append(tags, string(tag))
+	}
+
+	return template.HTML(strings.Join(tags, "\n")), nil
+}
+
+// Precompute renders and caches css and js tags of every entry, so [AssetMapper.CSSLinkTagsFromEntry]
+// and [AssetMapper.JSScriptTagsFromEntry] called without additional attributes are only a map lookup.
+// Cache is dropped by [AssetMapper.UseManifest], [AssetMapper.CreateEntry], [AssetMapper.AddAsset]
+// and so by every scan, so Precompute should be called again after them. Changes of options,
+// like PublicPath or Integrity, and of entries done directly are not detected, so Precompute
+// should be the last configuration call.
+func (a *AssetMapper) Precompute() error {
+	precomputed := map[string]entryTags{}
+
+	for name := range a.Entries {
+		css, err := a.cssLinkTags(name, nil)
+		if err != nil {
+			return err
+		}
+
+		js, err := a.jsScriptTags(name, nil)
+		if err != nil {
+			return err
+		}
+
+		precomputed[name] = entryTags{css: css, js: js}
+	}
+
+	a.precomputed = precomputed
+
+	return nil
+}
+
+// SetScriptPlacement sets placement hint for entry script. path can be script url from entry
+// or asset path resolved with [AssetMapper.Get].
+//
+// Example:
+//
+//	assetMapper.SetScriptPlacement("app", "src/analytics.js", asset.ScriptPlacementHead)
+func (a *AssetMapper) SetScriptPlacement(name, path string, placement ScriptPlacement) {
+	entry, ok := a.Entries[name]
+	if !ok {
+		return
+	}
+
+	if !slices.Contains(entry.JS, path) {
+		path = a.Get(path)
+	}
+	if entry.Placement == nil {
+		entry.Placement = map[string]ScriptPlacement{}
+	}
+	entry.Placement[path] = placement
+	a.precomputed = nil
+}
+
+// HeadScriptsFromEntry returns html scripts of entry with [ScriptPlacementHead] hint.
+//
+// For more information look [AssetMapper.JSScriptTagsFromEntry] method
+func (a *AssetMapper) HeadScriptsFromEntry(name string, attrs ...string) ([]template.HTML, error) {
+	return a.scriptTags(a.entryScripts(name, ScriptPlacementHead), a.entryAttrs(attrs))
+}
+
+// BodyScriptsFromEntry returns html scripts of entry with [ScriptPlacementBody] hint, which is default
+// for scripts without placement.
+//
+// For more information look [AssetMapper.JSScriptTagsFromEntry] method
+func (a *AssetMapper) BodyScriptsFromEntry(name string, attrs ...string) ([]template.HTML, error) {
+	return a.scriptTags(a.entryScripts(name, ScriptPlacementBody), a.entryAttrs(attrs))
+}
+
+func (a *AssetMapper) entryScripts(name string, placement ScriptPlacement) []string {
+	entry, ok := a.Entries[name]
+	if !ok {
+		return nil
+	}
+
+	urls := []string{}
+	for _, js := range entry.JS {
+		if entry.Placement[js] == placement {
+			urls = append(urls, js)
+		}
+	}
+
+	return a.publicURLs(urls)
+}
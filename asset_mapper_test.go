@@ -1,6 +1,23 @@
 package asset
 
-import "testing"
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
 
 func TestAssetMapperGet(t *testing.T) {
 	a := NewAssetMapper()
@@ -24,22 +41,1971 @@ func TestAssetMapperGet(t *testing.T) {
 	}
 }
 
-func TestAttributeToString(t *testing.T) {
-	s := attributeMapToString(map[string]string{
+func TestZeroHashLenSkipsVersion(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	a.HashLen = 0
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/app.js"
+	result := a.Get("app.js")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestScanDirSince(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.js", "style.css"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	style := a.Assets["style.css"]
+
+	since := time.Now()
+	for _, name := range []string{"app.js", "style.css"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+" changed"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// only app.js counts as touched after since
+	if err := os.Chtimes(filepath.Join(dir, "app.js"), since.Add(time.Minute), since.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "style.css"), since.Add(-time.Minute), since.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.ScanDirSince(dir, since); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/app.js?v=" + sha256Prefix("app.js changed", 10)
+	result := a.Get("app.js")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+	if a.Assets["style.css"] != style {
+		t.Errorf("Untouched asset should be kept. Got: %s\n", a.Get("style.css"))
+	}
+}
+
+func TestVersionStrategyMtime(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "video.mp4")
+	if err := os.WriteFile(file, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	scan := func() string {
+		a := NewAssetMapper()
+		a.Trim = dir + "/"
+		a.VersionStrategy = VersionStrategyMtime
+		if err := a.ScanDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		return a.Get("video.mp4")
+	}
+
+	before := scan()
+	expected := "/video.mp4?v=" + strconv.FormatInt(modTime.UnixNano(), 36) + "-5"
+	if expected != before {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, before)
+	}
+
+	// content is the same, only modification time changes
+	if err := os.Chtimes(file, modTime.Add(time.Second), modTime.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if after := scan(); after == before {
+		t.Errorf("Version should change after touching file. Got: %s\n", after)
+	}
+}
+
+func TestScanDirReplace(t *testing.T) {
+	theme := t.TempDir()
+	override := t.TempDir()
+	for dir, content := range map[string]string{theme: "theme", override: "override"} {
+		if err := os.WriteFile(filepath.Join(dir, "app.css"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scan := func(scanDir func(a *AssetMapper, dir string) error) string {
+		a := NewAssetMapper()
+		a.StripScanRoot = true
+		for _, dir := range []string{theme, override} {
+			if err := scanDir(a, dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return a.Get("app.css")
+	}
+
+	expected := "/app.css?v=" + sha256Prefix("theme", 10)
+	result := scan((*AssetMapper).ScanDir)
+	if expected != result {
+		t.Errorf("First scanned file should win. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	expected = "/app.css?v=" + sha256Prefix("override", 10)
+	result = scan((*AssetMapper).ScanDirReplace)
+	if expected != result {
+		t.Errorf("Last scanned file should win. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("app.js"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	current := atomic.Pointer[AssetMapper]{}
+	current.Store(a.Snapshot())
+
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("app.js changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ScanDirReplace(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/app.js?v=" + sha256Prefix("app.js", 10)
+	result := current.Load().Get("app.js")
+	if expected != result {
+		t.Errorf("Snapshot should keep old hash. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	expected = "/app.js?v=" + sha256Prefix("app.js changed", 10)
+	result = a.Get("app.js")
+	if expected != result {
+		t.Errorf("Rescanned mapper should get new hash. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	current.Store(a.Snapshot())
+	result = current.Load().Get("app.js")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestLargeAssetWarning(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "small.png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "huge.png"), []byte(strings.Repeat("x", 2048)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	large := []string{}
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.LargeAssetSize = 1024
+	a.OnLargeAsset = func(asset *Asset) {
+		large = append(large, asset.Path)
+	}
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(large) != 1 || large[0] != "huge.png" {
+		t.Errorf("Only oversized asset should be reported. Got: %v\n", large)
+	}
+	if a.Assets["small.png"].Size != 3 {
+		t.Errorf("Asset size should be recorded. Got: %d\n", a.Assets["small.png"].Size)
+	}
+}
+
+func TestMaxScanDepth(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "js", "vendor", "deep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.js", "js/app.js", "js/vendor/lib.js", "js/vendor/deep/lib.js"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.MaxScanDepth = 1
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, mapped := range map[string]bool{"app.js": true, "js/app.js": true, "js/vendor/lib.js": false, "js/vendor/deep/lib.js": false} {
+		if _, ok := a.Assets[name]; ok != mapped {
+			t.Errorf("Unexpected mapping of %s. Expected: %t\n", name, mapped)
+		}
+	}
+}
+
+func TestHashLenByKind(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"logo.png", "app.js"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashLen = 16
+	a.HashLenByKind = map[Kind]int{KindImage: 6}
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if hash := a.Assets["logo.png"].Hash; hash != sha256Prefix("logo.png", 6) {
+		t.Errorf("Image should get short hash. Got: %s\n", hash)
+	}
+	if hash := a.Assets["app.js"].Hash; hash != sha256Prefix("app.js", 16) {
+		t.Errorf("Script should get HashLen hash. Got: %s\n", hash)
+	}
+}
+
+func TestHashLenByKindRange(t *testing.T) {
+	fsys := fstest.MapFS{"assets/logo.png": {Data: []byte("logo.png")}}
+
+	for _, n := range []int{-1, 65} {
+		a := NewAssetMapper()
+		a.HashLenByKind = map[Kind]int{KindImage: n}
+		if err := a.ScanFS(fsys, "assets"); !errors.Is(err, ErrInvalidHashLen) {
+			t.Errorf("Hash length %d should be rejected. Got: %v\n", n, err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.HashLenByKind = map[Kind]int{KindImage: 64}
+	if err := a.ScanFS(fsys, "assets"); err != nil {
+		t.Fatal(err)
+	}
+	if hash := a.Assets["logo.png"].Hash; hash != sha256Prefix("logo.png", 64) {
+		t.Errorf("Image should get full hash. Got: %s\n", hash)
+	}
+}
+
+func TestRelativeURLs(t *testing.T) {
+	a := NewAssetMapper()
+	a.RelativeURLs = true
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app.css")
+
+	expected := "app.js?v=123"
+	result := a.Get("app.js")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	tag, err := a.CSSLinkTagsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tag) != 1 || string(tag[0]) != `<link href="assets/app.css" rel="stylesheet"/>` {
+		t.Errorf("Entry url should be relative. Got: %v\n", tag)
+	}
+
+	a.PublicPath = "https://cdn.example.com/"
+	a.Assets["app.js"].PublicPath = a.PublicPath
+	expected = "https://cdn.example.com/app.js?v=123"
+	if result := a.Get("app.js"); expected != result {
+		t.Errorf("Absolute url should be kept. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestCheckURLCollisions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.js", "admin.js"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashLen = 1
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CheckURLCollisions(); err != nil {
+		t.Fatalf("Scanned assets should not collide. Got: %v\n", err)
+	}
+
+	// build tool with one character hashes produced the same file name for different chunks
+	for _, src := range []string{"src/app.js", "src/admin.js"} {
+		a.AddAsset(&Asset{Path: src, File: "assets/chunk-" + a.Assets["app.js"].Hash + ".js", PublicPath: a.PublicPath, Fingerprinted: true}, false)
+	}
+
+	err := a.CheckURLCollisions()
+	if !errors.Is(err, ErrURLCollision) {
+		t.Fatalf("Collision should be reported. Got: %v\n", err)
+	}
+	if !strings.Contains(err.Error(), "src/admin.js") || !strings.Contains(err.Error(), "src/app.js") {
+		t.Errorf("Error should list colliding assets. Got: %v\n", err)
+	}
+}
+
+func TestBuildID(t *testing.T) {
+	a := NewAssetMapper()
+	a.AddAsset(&Asset{Path: "app.js", Hash: "123", PublicPath: a.PublicPath}, false)
+
+	before := a.BuildID()
+	if before != a.BuildID() {
+		t.Errorf("BuildID should be stable\n")
+	}
+	if len(before) != 16 {
+		t.Errorf("Unexpected BuildID length: %s\n", before)
+	}
+
+	a.AddAsset(&Asset{Path: "style.css", Hash: "456", PublicPath: a.PublicPath}, false)
+	if before == a.BuildID() {
+		t.Errorf("BuildID should change after adding asset\n")
+	}
+}
+
+func TestRenderAttributes(t *testing.T) {
+	s := renderAttributes(map[string]string{
 		"data-test": "value",
-	})
+	}, '"')
 
 	expected := "data-test=\"value\""
 	if s != expected {
 		t.Errorf("String should be equal. Expected: \"%s\"\nGot: \"%s\"\n", expected, s)
 	}
 
-	s = attributeMapToString(map[string]string{
+	s = renderAttributes(map[string]string{
 		"shouldEscape<>": ">",
-	})
+	}, '"')
 
 	expected = "shouldEscape&lt;&gt;=\"&gt;\""
 	if s != expected {
 		t.Errorf("String should be equal. Expected: \"%s\"\nGot: \"%s\"\n", expected, s)
 	}
+
+	s = renderAttributes(map[string]string{
+		"defer": "",
+		"title": "it's",
+	}, '\'')
+
+	expected = "defer title='it&#39;s'"
+	if s != expected {
+		t.Errorf("String should be equal. Expected: \"%s\"\nGot: \"%s\"\n", expected, s)
+	}
+}
+
+func TestUnusedAssets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.css", "app.js", "unused.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := a.CreateEntry("app")
+	entry.CSS = append(entry.CSS, a.Get("app.css"))
+	entry.JS = append(entry.JS, a.Get("app.js"))
+
+	unused := a.UnusedAssets()
+	if len(unused) != 1 || unused[0].Path != "unused.png" {
+		t.Errorf("Only unused.png should be reported. Got: %v\n", unused)
+	}
+}
+
+func TestPublicPathPrefix(t *testing.T) {
+	cases := map[string]string{
+		"":                                "/",
+		"/":                               "/",
+		"static":                          "/static/",
+		"/static":                         "/static/",
+		"/static/":                        "/static/",
+		"https://cdn.example.com/assets/": "/assets/",
+	}
+
+	a := NewAssetMapper()
+	for publicPath, expected := range cases {
+		a.PublicPath = publicPath
+		result := a.PublicPathPrefix()
+		if expected != result {
+			t.Errorf("String should be equal for %q. Expected: %s\nGot:%s\n", publicPath, expected, result)
+		}
+	}
+}
+
+func TestScanRoots(t *testing.T) {
+	frontend := t.TempDir()
+	legacy := t.TempDir()
+
+	files := map[string]string{
+		filepath.Join(frontend, "app.js"):    "app",
+		filepath.Join(frontend, "shared.js"): "frontend shared",
+		filepath.Join(legacy, "old.js"):      "old",
+		filepath.Join(legacy, "shared.js"):   "legacy shared",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+
+	err := a.ScanRoots(frontend, legacy)
+	if !errors.Is(err, ErrAssetCollision) {
+		t.Fatalf("Collision error expected. Got: %v\n", err)
+	}
+
+	for _, path := range []string{"app.js", "old.js", "shared.js"} {
+		if _, ok := a.Assets[path]; !ok {
+			t.Errorf("Asset %s should be mapped relative to its root\n", path)
+		}
+	}
+
+	frontendShared, _ := NewAsset(mustOpen(t, filepath.Join(frontend, "shared.js")), "shared.js", a.PublicPath, a.HashLen)
+	if a.Assets["shared.js"].Hash != frontendShared.Hash {
+		t.Errorf("First root should win collision\n")
+	}
+}
+
+func mustOpen(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lf.css":   "body {\n  color: red;\n}\n",
+		"crlf.css": "\xef\xbb\xbfbody {\r\n  color: red;\r\n}\r\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if a.Assets["lf.css"].Hash == a.Assets["crlf.css"].Hash {
+		t.Errorf("Hashes should differ without normalization\n")
+	}
+
+	a = NewAssetMapper()
+	a.Trim = dir + "/"
+	a.NormalizeLineEndings = true
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if a.Assets["lf.css"].Hash != a.Assets["crlf.css"].Hash {
+		t.Errorf("Hashes should be equal with normalization. Got: %s and %s\n", a.Assets["lf.css"].Hash, a.Assets["crlf.css"].Hash)
+	}
+}
+
+func TestLinkTagAlternateDisabledStylesheet(t *testing.T) {
+	a := NewAssetMapper()
+
+	tag, err := a.LinkTag("dark.css", "rel", "alternate stylesheet", "title", "Dark", "disabled", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := string(tag)
+	for _, expected := range []string{`rel="alternate stylesheet"`, `title="Dark"`, ` disabled`, `href="dark.css"`} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Link tag should contain %s. Got: %s\n", expected, s)
+		}
+	}
+	if strings.Contains(s, `disabled=`) {
+		t.Errorf("disabled should be boolean attribute. Got: %s\n", s)
+	}
+}
+
+func TestWebManifestTag(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["manifest.webmanifest"] = &Asset{Path: "manifest.webmanifest", Hash: "123", PublicPath: "/"}
+
+	tag, err := a.WebManifestTag("manifest.webmanifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<link href="/manifest.webmanifest?v=123" rel="manifest"/>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err = a.WebManifestTag("manifest.webmanifest", "crossorigin", "use-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `<link crossorigin="use-credentials" href="/manifest.webmanifest?v=123" rel="manifest"/>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+}
+
+func TestIconTag(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["favicon-32x32.png"] = &Asset{
+		Path:       "favicon-32x32.png",
+		Hash:       "123",
+		PublicPath: "/",
+	}
+
+	tag, err := a.IconTag("favicon-32x32.png", "32x32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := string(tag)
+	for _, expected := range []string{`rel="icon"`, `sizes="32x32"`, `href="/favicon-32x32.png?v=123"`} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Icon tag should contain %s. Got: %s\n", expected, s)
+		}
+	}
+
+	tag, err = a.AppleTouchIconTag("apple-touch-icon.png", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s = string(tag)
+	if !strings.Contains(s, `rel="apple-touch-icon"`) || strings.Contains(s, "sizes") {
+		t.Errorf("Apple touch icon tag should have rel and no sizes. Got: %s\n", s)
+	}
+}
+
+func BenchmarkRenderAttributes(b *testing.B) {
+	m := map[string]string{
+		"src":         "/assets/app.js?v=0123456789",
+		"type":        "module",
+		"id":          "app-script",
+		"crossorigin": "anonymous",
+		"defer":       "",
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		renderAttributes(m, '"')
+	}
+}
+
+func sha256Prefix(content string, n int) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:n]
+}
+
+func TestHashCacheSidecar(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := t.TempDir()
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashCache = true
+	a.HashCacheDir = cacheDir
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	sidecar := a.hashCachePath(dir)
+	if filepath.Dir(sidecar) != cacheDir {
+		t.Errorf("Sidecar should be written to HashCacheDir. Got: %s\n", sidecar)
+	}
+	content, err := os.ReadFile(sidecar)
+	if err != nil {
+		t.Fatalf("Sidecar should be written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, hashCacheFile)); err == nil {
+		t.Errorf("Sidecar should not be written inside scanned directory\n")
+	}
+
+	// replace cached hash, so second scan proves it was read instead of hashing the file
+	realHash := a.Assets["app.js"].Hash
+	content = []byte(strings.Replace(string(content), realHash, "cachedhash", 1))
+	if err := os.WriteFile(sidecar, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a = NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashCache = true
+	a.HashCacheDir = cacheDir
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "cachedhash"
+	result := a.Assets["app.js"].Hash
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestHashCacheLegacySidecar(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"app.js": "app", hashCacheFile: "{}"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := a.Assets[hashCacheFile]; ok {
+		t.Errorf("Sidecar should not be mapped as asset\n")
+	}
+}
+
+func TestHashCacheSaveError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// regular file in place of cache directory can't be written to
+	cacheDir := filepath.Join(dir, "cache")
+	if err := os.WriteFile(cacheDir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashCache = true
+	a.HashCacheDir = cacheDir
+	a.Logger = slog.New(slog.NewTextHandler(&out, nil))
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatalf("Scan should not fail when sidecar can't be written: %v", err)
+	}
+
+	if _, ok := a.Assets["app.js"]; !ok {
+		t.Errorf("Asset should be mapped\n")
+	}
+	if !strings.Contains(out.String(), `level=WARN msg="hash cache not saved"`) {
+		t.Errorf("Failed write should be logged. Got: %s\n", out.String())
+	}
+}
+
+func TestHashCacheScanDirSince(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.js", "style.css"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := bytes.Buffer{}
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashCache = true
+	a.HashCacheDir = t.TempDir()
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	since := time.Now()
+	modified := since.Add(time.Minute)
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "style.css"), modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ScanDirSince(dir, since); err != nil {
+		t.Fatal(err)
+	}
+
+	// full scan after quick rescan still reuses hash of file skipped by it
+	a.Logger = slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if err := a.ScanDirReplace(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	result := out.String()
+	for _, expected := range []string{
+		`msg="hash cache hit" file=app.js`,
+		`msg="hash cache hit" file=style.css`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Log should contain %s\nGot:%s\n", expected, result)
+		}
+	}
+}
+
+func TestHashCacheOptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("app\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := t.TempDir()
+	scan := func(configure func(a *AssetMapper)) string {
+		a := NewAssetMapper()
+		a.Trim = dir + "/"
+		a.HashCacheDir = cacheDir
+		configure(a)
+		if err := a.ScanDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		return a.Get("app.js")
+	}
+
+	options := map[string]func(a *AssetMapper){
+		"HashIncludesPath":     func(a *AssetMapper) { a.HashIncludesPath = true },
+		"NormalizeLineEndings": func(a *AssetMapper) { a.NormalizeLineEndings = true },
+	}
+	for name, option := range options {
+		expected := scan(option)
+
+		// sidecar is written without option, then reused with it
+		scan(func(a *AssetMapper) { a.HashCache = true })
+		result := scan(func(a *AssetMapper) {
+			a.HashCache = true
+			option(a)
+		})
+		if expected != result {
+			t.Errorf("Cache should be invalidated by %s. Expected: %s\nGot:%s\n", name, expected, result)
+		}
+	}
+}
+
+func TestHashCacheIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := t.TempDir()
+	scan := func() *AssetMapper {
+		a := NewAssetMapper()
+		a.Trim = dir + "/"
+		a.HashCache = true
+		a.HashCacheDir = cacheDir
+		a.Integrity = IntegrityAlways
+		if err := a.ScanDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	sum := sha256.Sum256([]byte("app"))
+	expected := `<script crossorigin="anonymous" integrity="sha256-` + base64.StdEncoding.EncodeToString(sum[:]) +
+		`" src="/app.js?v=` + sha256Prefix("app", 10) + `"></script>`
+
+	// second scan reads hash from sidecar
+	for i := range 2 {
+		a := scan()
+		tag, err := a.ScriptTag("app.js")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != string(tag) {
+			t.Errorf("String should be equal in scan %d. Expected: %s\nGot:%s\n", i+1, expected, tag)
+		}
+		if scriptSrc, _ := a.CSPHashes(); len(scriptSrc) != 1 {
+			t.Errorf("CSP hash should be returned in scan %d. Got: %v\n", i+1, scriptSrc)
+		}
+	}
+}
+
+func TestEntryHTML(t *testing.T) {
+	a := NewAssetMapper()
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app-CKgRTByK.js")
+	entry.Add("/assets/app-o2N34dPp.css")
+
+	result, err := a.EntryHTML("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<link href="/assets/app-o2N34dPp.css" rel="stylesheet"/>` + "\n" + `<script src="/assets/app-CKgRTByK.js"></script>`
+	if expected != string(result) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestTagAttributeValuesEscaping(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["app.js"] = &Asset{
+		Path:       "app.js",
+		Hash:       "123",
+		PublicPath: "/",
+	}
+
+	tag, err := a.ScriptTag("app.js", "data-config", `{"title": "Tom & Jerry's"}`, "data-url", "/api?a=1&b=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<script data-config="{&#34;title&#34;: &#34;Tom &amp; Jerry&#39;s&#34;}" data-url="/api?a=1&amp;b=2" src="/app.js?v=123"></script>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err = a.LinkTag("style.css?theme=dark&v=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `<link href="style.css?theme=dark&amp;v=2" rel="stylesheet"/>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	for _, name := range []string{"", "data value", `onload="x"`, "a>b"} {
+		if _, err := a.ScriptTag("app.js", name, "value"); err == nil {
+			t.Errorf("Attribute name %q should be rejected\n", name)
+		}
+	}
+}
+
+func TestOddAttributes(t *testing.T) {
+	a := NewAssetMapper()
+
+	if _, err := a.ScriptTag("app.js", "defer"); !errors.Is(err, ErrOddAttributes) {
+		t.Errorf("Odd attributes error should match ErrOddAttributes. Got: %v\n", err)
+	}
+	if _, err := a.JSScriptTagsFromEntry("app", "type", "module", "async"); !errors.Is(err, ErrOddAttributes) {
+		t.Errorf("Odd attributes error should match ErrOddAttributes. Got: %v\n", err)
+	}
+}
+
+func TestPostScanHook(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.css", "app.js"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := []string{}
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.PostScan = func(asset *Asset) error {
+		paths = append(paths, asset.Path)
+		return nil
+	}
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(paths, ",") != "app.css,app.js" {
+		t.Errorf("Hook should be called for each asset. Got: %v\n", paths)
+	}
+
+	hookErr := errors.New("hook failed")
+	a = NewAssetMapper()
+	a.PostScan = func(asset *Asset) error {
+		return hookErr
+	}
+	if err := a.ScanDir(dir); !errors.Is(err, hookErr) {
+		t.Errorf("Hook error should abort scan. Got: %v\n", err)
+	}
+	if len(a.Assets) != 0 {
+		t.Errorf("No assets should be added after hook error. Got: %d\n", len(a.Assets))
+	}
+}
+
+func TestPathAlias(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["src/logo.png"] = &Asset{
+		Path:       "src/logo.png",
+		Hash:       "123",
+		PublicPath: "/",
+	}
+	a.AddPathAlias("@/", "src/")
+
+	expected := "/src/logo.png?v=123"
+	result := a.Get("@/logo.png")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	expected = "@/missing.png"
+	result = a.Get(expected)
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestPrecompute(t *testing.T) {
+	a := NewAssetMapper()
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app.js")
+
+	if err := a.Precompute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// changes made after Precompute are not visible until cache is dropped
+	entry.Add("/assets/vendor.js")
+	tags, _ := a.JSScriptTagsFromEntry("app")
+	if len(tags) != 1 {
+		t.Errorf("Precomputed tags should be used. Got: %v\n", tags)
+	}
+
+	tags, _ = a.JSScriptTagsFromEntry("app", "defer", "")
+	if len(tags) != 2 {
+		t.Errorf("Tags with attributes should be rendered on demand. Got: %v\n", tags)
+	}
+
+	a.CreateEntry("admin")
+	tags, _ = a.JSScriptTagsFromEntry("app")
+	if len(tags) != 2 {
+		t.Errorf("Cache should be dropped after entry is created. Got: %v\n", tags)
+	}
+}
+
+func benchmarkEntryMapper() *AssetMapper {
+	a := NewAssetMapper()
+	entry := a.CreateEntry("app")
+	for _, path := range []string{"app", "vendor", "runtime", "shared"} {
+		entry.Add("/assets/" + path + "-CKgRTByK.js")
+		entry.Add("/assets/" + path + "-o2N34dPp.css")
+	}
+	return a
+}
+
+func TestPrecomputeRescan(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.CreateEntry("app").Add("/app.js")
+
+	for name, change := range map[string]func() error{
+		"ScanDirReplace": func() error { return a.ScanDirReplace(dir) },
+		"AddAsset": func() error {
+			a.AddAsset(&Asset{Path: "other.js", Hash: "123", PublicPath: a.PublicPath}, false)
+			return nil
+		},
+		"MarkVolatile": func() error {
+			a.MarkVolatile("app.js")
+			return nil
+		},
+	} {
+		if err := a.Precompute(); err != nil {
+			t.Fatal(err)
+		}
+		if err := change(); err != nil {
+			t.Fatal(err)
+		}
+		if a.precomputed != nil {
+			t.Errorf("Precomputed tags should be dropped by %s\n", name)
+		}
+	}
+}
+
+func BenchmarkEntryTags(b *testing.B) {
+	b.Run("on-demand", func(b *testing.B) {
+		a := benchmarkEntryMapper()
+
+		b.ReportAllocs()
+		for b.Loop() {
+			a.CSSLinkTagsFromEntry("app")
+			a.JSScriptTagsFromEntry("app")
+		}
+	})
+
+	b.Run("precomputed", func(b *testing.B) {
+		a := benchmarkEntryMapper()
+		if err := a.Precompute(); err != nil {
+			b.Fatal(err)
+		}
+
+		b.ReportAllocs()
+		for b.Loop() {
+			a.CSSLinkTagsFromEntry("app")
+			a.JSScriptTagsFromEntry("app")
+		}
+	})
+}
+
+func TestWithPublicPath(t *testing.T) {
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	a.AddAsset(&Asset{Path: "css/app.css", File: "css/app.css", Hash: "123", PublicPath: a.PublicPath}, false)
+	a.AddAsset(&Asset{Path: "src/app.js", File: "assets/app-CKgRTByK.js", PublicPath: a.PublicPath, Fingerprinted: true}, false)
+	a.CreateEntry("app").Add(a.Get("src/app.js"))
+
+	cases := map[string][]string{
+		"/acme/static/":  {"/acme/static/css/app.css?v=123", "/acme/static/assets/app-CKgRTByK.js"},
+		"/globex/static": {"/globex/static/css/app.css?v=123", "/globex/static/assets/app-CKgRTByK.js"},
+	}
+	for prefix, expected := range cases {
+		tenant := a.WithPublicPath(prefix)
+
+		if result := tenant.Get("css/app.css"); result != expected[0] {
+			t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected[0], result)
+		}
+		if result := tenant.Get("src/app.js"); result != expected[1] {
+			t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected[1], result)
+		}
+
+		tags, err := tenant.JSScriptTagsFromEntry("app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tags) != 1 || !strings.Contains(string(tags[0]), `src="`+expected[1]+`"`) {
+			t.Errorf("Entry script should use tenant prefix. Got: %v\n", tags)
+		}
+	}
+
+	expected := "/static/css/app.css?v=123"
+	if result := a.Get("css/app.css"); result != expected {
+		t.Errorf("Base mapper should keep its prefix. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestPathTraversalRejected(t *testing.T) {
+	a := NewAssetMapper()
+
+	for _, path := range []string{"../../etc/passwd", "/assets/../../etc/passwd", "assets\\..\\secret.txt"} {
+		if result := a.Get(path); result != "" {
+			t.Errorf("Traversal path %s should resolve to empty string. Got: %s\n", path, result)
+		}
+		if _, err := a.ScriptTag(path); !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("Script tag for %s should return ErrPathTraversal. Got: %v\n", path, err)
+		}
+		if _, err := a.LinkTag(path); !errors.Is(err, ErrPathTraversal) {
+			t.Errorf("Link tag for %s should return ErrPathTraversal. Got: %v\n", path, err)
+		}
+	}
+
+	expected := "assets/..hidden/app.js"
+	if result := a.Get(expected); result != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.js", "style.css"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := bytes.Buffer{}
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashCache = true
+	a.HashCacheDir = t.TempDir()
+	a.Logger = slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UseManifest(ManifestConfig{Path: writeManifest(t, multiEntryViteManifest), Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := out.String()
+	for _, expected := range []string{
+		`level=DEBUG msg="hash cache miss" file=app.js`,
+		`level=DEBUG msg="hash cache hit" file=style.css`,
+		`level=DEBUG msg="directory scanned" dir=` + dir + ` files=2 errors=0`,
+		`level=DEBUG msg="manifest loaded"`,
+		`entries=2`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Log should contain %s\nGot:%s\n", expected, result)
+		}
+	}
+}
+
+func TestScanDirContinueOnError(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.css", "app.js"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// dangling symlink can't be opened regardless of user permissions
+	if err := os.Symlink(filepath.Join(dir, "missing.js"), filepath.Join(dir, "broken.js")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err == nil {
+		t.Errorf("Scan should fail on unreadable file by default\n")
+	}
+
+	a = NewAssetMapper()
+	a.Trim = dir + "/"
+	a.ContinueOnError = true
+	err := a.ScanDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.js") {
+		t.Errorf("Aggregated error should mention unreadable file. Got: %v\n", err)
+	}
+
+	for _, path := range []string{"app.css", "app.js"} {
+		if _, ok := a.Assets[path]; !ok {
+			t.Errorf("Readable file %s should be mapped\n", path)
+		}
+	}
+	if _, ok := a.Assets["broken.js"]; ok {
+		t.Errorf("Unreadable file should not be mapped\n")
+	}
+}
+
+func TestPreconnectTag(t *testing.T) {
+	a := NewAssetMapper()
+
+	tag, err := a.PreconnectTag()
+	if err != nil || tag != "" {
+		t.Errorf("Preconnect should be empty for relative public path. Got: %s, %v\n", tag, err)
+	}
+
+	a.PublicPath = "https://cdn.example.com/static/"
+	tag, err = a.PreconnectTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<link href="https://cdn.example.com" rel="preconnect"/>` + "\n" + `<link href="https://cdn.example.com" rel="dns-prefetch"/>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+}
+
+func TestScriptPlacement(t *testing.T) {
+	a := NewAssetMapper()
+	a.AddAsset(&Asset{Path: "src/analytics.js", File: "assets/analytics-B7PI925R.js", PublicPath: a.PublicPath, Fingerprinted: true}, false)
+
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app-CKgRTByK.js")
+	entry.Add("/assets/analytics-B7PI925R.js")
+	entry.Add("/assets/vendor-Dq1cWbUa.js")
+
+	a.SetScriptPlacement("app", "src/analytics.js", ScriptPlacementHead)
+	a.SetScriptPlacement("app", "/assets/vendor-Dq1cWbUa.js", ScriptPlacementHead)
+
+	head, err := a.HeadScriptsFromEntry("app", "defer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`<script defer src="/assets/analytics-B7PI925R.js"></script>`,
+		`<script defer src="/assets/vendor-Dq1cWbUa.js"></script>`,
+	}
+	if len(head) != len(expected) || string(head[0]) != expected[0] || string(head[1]) != expected[1] {
+		t.Errorf("Unexpected head scripts. Expected: %v\nGot:%v\n", expected, head)
+	}
+
+	body, err := a.BodyScriptsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 1 || string(body[0]) != `<script src="/assets/app-CKgRTByK.js"></script>` {
+		t.Errorf("Scripts without placement should be in body. Got: %v\n", body)
+	}
+}
+
+func TestFetchPriority(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["hero.webp"] = &Asset{Path: "hero.webp", Hash: "123", PublicPath: "/"}
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "456", PublicPath: "/"}
+
+	tag, err := a.ImgTag("hero.webp", "alt", "Hero", "fetchpriority", "high")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<img alt="Hero" fetchpriority="high" src="/hero.webp?v=123"/>`
+	if string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err = a.PreloadTag("hero.webp", "fetchpriority", "high")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<link as="image" fetchpriority="high" href="/hero.webp?v=123" rel="preload"/>`
+	if string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err = a.ScriptTag("app.js", "fetchpriority", "low")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<script fetchpriority="low" src="/app.js?v=456"></script>`
+	if string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	if _, err := a.ImgTag("hero.webp", "fetchpriority", "urgent"); !errors.Is(err, ErrInvalidFetchPriority) {
+		t.Errorf("Invalid fetchpriority should be rejected. Got: %v\n", err)
+	}
+	if _, err := a.PreloadTag("hero.webp", "fetchpriority", ""); !errors.Is(err, ErrInvalidFetchPriority) {
+		t.Errorf("Empty fetchpriority should be rejected. Got: %v\n", err)
+	}
+}
+
+func TestPreloadTagFont(t *testing.T) {
+	a := NewAssetMapper()
+
+	tag, err := a.PreloadTag("fonts/inter.woff2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<link as="font" crossorigin="" href="fonts/inter.woff2" rel="preload"/>`
+	if string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+}
+
+func TestPreloadTagJSON(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["data/products.json"] = &Asset{Path: "data/products.json", Hash: "123", PublicPath: "/"}
+
+	tag, err := a.PreloadTag("data/products.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<link as="fetch" crossorigin="" href="/data/products.json?v=123" rel="preload"/>`
+	if string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err = a.PreloadTag("data/products.json", "as", "script", "crossorigin", "use-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<link as="script" crossorigin="use-credentials" href="/data/products.json?v=123" rel="preload"/>`
+	if string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+}
+
+func TestNoScript(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+	a.CreateEntry("app").Add("/assets/app-CKgRTByK.js")
+
+	if tag := a.NoScriptTag(); tag != "" {
+		t.Errorf("Noscript should not be rendered by default. Got: %s\n", tag)
+	}
+
+	a.NoScript = `<p>Please enable JavaScript.</p>`
+
+	expected := `<noscript><p>Please enable JavaScript.</p></noscript>`
+	if tag := a.NoScriptTag(); string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err := a.ScriptTag("app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<script src="/app.js?v=123"></script>`
+	if string(tag) != expected {
+		t.Errorf("Script tag should not include noscript. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tags, err := a.JSScriptTagsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 {
+		t.Errorf("Entry scripts should not include noscript. Got: %v\n", tags)
+	}
+
+	tag, err = a.ScriptTag("app.js", "noscript", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<script src="/app.js?v=123"></script><noscript><p>Please enable JavaScript.</p></noscript>`
+	if string(tag) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	a.CreateEntry("app").Add("/assets/vendor-B7PI925R.js")
+	tags, err = a.JSScriptTagsFromEntry("app", "noscript", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 || string(tags[2]) != `<noscript><p>Please enable JavaScript.</p></noscript>` {
+		t.Errorf("Noscript should follow entry scripts once. Got: %v\n", tags)
+	}
+
+	tags, err = a.BodyScriptsFromEntry("app", "noscript", "false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Errorf("Noscript should not be rendered with false option. Got: %v\n", tags)
+	}
+}
+
+func TestDeferByDefault(t *testing.T) {
+	a := NewAssetMapper()
+	a.DeferByDefault = true
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app-CKgRTByK.js")
+
+	cases := []struct {
+		attrs    []string
+		expected string
+	}{
+		{nil, `<script defer src="/app.js?v=123"></script>`},
+		{[]string{"async", ""}, `<script async src="/app.js?v=123"></script>`},
+		{[]string{"type", "module"}, `<script src="/app.js?v=123" type="module"></script>`},
+		{[]string{"defer", ""}, `<script defer src="/app.js?v=123"></script>`},
+		{[]string{"defer", "false"}, `<script src="/app.js?v=123"></script>`},
+	}
+	for _, c := range cases {
+		tag, err := a.ScriptTag("app.js", c.attrs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.expected != string(tag) {
+			t.Errorf("String should be equal for %v. Expected: %s\nGot:%s\n", c.attrs, c.expected, tag)
+		}
+	}
+
+	scripts, err := a.JSScriptTagsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<script defer src="/assets/app-CKgRTByK.js"></script>`
+	if len(scripts) != 1 || string(scripts[0]) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%v\n", expected, scripts)
+	}
+
+	scripts, err = a.JSScriptTagsFromEntry("app", "defer", "false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<script src="/assets/app-CKgRTByK.js"></script>`
+	if len(scripts) != 1 || string(scripts[0]) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%v\n", expected, scripts)
+	}
+
+	a.DeferByDefault = false
+	tag, err := a.ScriptTag("app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<script src="/app.js?v=123"></script>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	// override is applied only with DeferByDefault, otherwise attributes are rendered as passed
+	tag, err = a.ScriptTag("app.js", "defer", "false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<script defer src="/app.js?v=123"></script>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+}
+
+func TestTagQueryPassthrough(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+	a.AddAsset(&Asset{Path: "src/main.js", File: "assets/main-CKgRTByK.js", PublicPath: "/", Fingerprinted: true}, false)
+
+	tag, err := a.ScriptTag("app.js?nocache=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<script src="/app.js?v=123&amp;nocache=1"></script>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	cases := map[string]string{
+		"src/main.js?nocache=1": "/assets/main-CKgRTByK.js?nocache=1",
+		"app.js#main":           "/app.js?v=123#main",
+		"missing.js?nocache=1":  "missing.js?nocache=1",
+	}
+	for path, expected := range cases {
+		if result := a.Get(path); expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, expected, result)
+		}
+	}
+}
+
+func TestGetIdempotent(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+
+	once := a.Get("app.js")
+	cases := map[string]string{
+		once:                    "/app.js?v=123",
+		"app.js?v=123#main":     "/app.js?v=123#main",
+		"app.js?v=123&debug=1":  "/app.js?v=123&debug=1",
+		"app.js?debug=1&v=123":  "/app.js?v=123&debug=1",
+		"app.js?v=456":          "/app.js?v=123",
+		"app.js?v=456&v":        "/app.js?v=123",
+		a.Get("app.js?debug=1"): "/app.js?v=123&debug=1",
+	}
+	for path, expected := range cases {
+		if result := a.Get(path); expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, expected, result)
+		}
+	}
+}
+
+func TestGetIdempotentPublicPath(t *testing.T) {
+	for _, publicPath := range []string{"/static/", "static", "https://cdn.example.com/static/"} {
+		a := NewAssetMapper()
+		a.PublicPath = publicPath
+		a.AddAsset(&Asset{Path: "app.js", File: "app.js", Hash: "123", PublicPath: publicPath}, false)
+		a.AddAsset(&Asset{Path: "src/main.js", File: "assets/main-CKgRTByK.js", PublicPath: publicPath, Fingerprinted: true}, false)
+
+		for _, path := range []string{"app.js", "app.js?debug=1#main", "src/main.js"} {
+			expected := a.Get(path)
+			if result := a.Get(expected); expected != result {
+				t.Errorf("String should be equal for %s with %s. Expected: %s\nGot:%s\n", path, publicPath, expected, result)
+			}
+		}
+	}
+}
+
+func TestEntryOtherFiles(t *testing.T) {
+	a := NewAssetMapper()
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app-CKgRTByK.js")
+	entry.Add("/assets/engine-B7PI925R.wasm")
+
+	expected := []string{"/assets/engine-B7PI925R.wasm"}
+	result := a.OtherEntry("app")
+	if strings.Join(expected, ",") != strings.Join(result, ",") {
+		t.Errorf("Unexpected entry files. Expected: %v\nGot:%v\n", expected, result)
+	}
+	if len(a.JSEntry("app")) != 1 || len(a.CSSEntry("app")) != 0 {
+		t.Errorf("Wasm should not be added to js or css. Got: %v %v\n", a.JSEntry("app"), a.CSSEntry("app"))
+	}
+}
+
+func TestModuleScriptExtensions(t *testing.T) {
+	for _, path := range []string{"app.mjs", "legacy.cjs", "app.js"} {
+		if !isJS(path) || kindOf(path) != KindJS {
+			t.Errorf("%s should be recognized as js\n", path)
+		}
+	}
+
+	a := NewAssetMapper()
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app-CKgRTByK.mjs")
+	entry.Add("/assets/legacy-B7PI925R.cjs")
+
+	expected := []string{"/assets/app-CKgRTByK.mjs", "/assets/legacy-B7PI925R.cjs"}
+	if strings.Join(expected, ",") != strings.Join(a.JSEntry("app"), ",") {
+		t.Errorf("Unexpected entry js. Expected: %v\nGot:%v\n", expected, a.JSEntry("app"))
+	}
+}
+
+func TestStylesheetSourceFallback(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["css/app.css"] = &Asset{Path: "css/app.css", Hash: "123", PublicPath: "/"}
+	a.Assets["css/theme.scss"] = &Asset{Path: "css/theme.scss", Hash: "456", PublicPath: "/"}
+	a.Assets["css/theme.css"] = &Asset{Path: "css/theme.css", Hash: "789", PublicPath: "/"}
+
+	cases := map[string]string{
+		"css/app.scss":   "/css/app.css?v=123",
+		"css/app.less":   "/css/app.css?v=123",
+		"css/theme.scss": "/css/theme.scss?v=456",
+		"css/other.scss": "css/other.scss",
+	}
+	for path, expected := range cases {
+		if result := a.Get(path); expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, expected, result)
+		}
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	a := NewAssetMapper()
+	if !a.IsEmpty() {
+		t.Errorf("Fresh mapper should be empty\n")
+	}
+
+	a.CreateEntry("app")
+	if a.IsEmpty() {
+		t.Errorf("Mapper with entry should not be empty\n")
+	}
+
+	a = NewAssetMapper()
+	a.AddAsset(&Asset{Path: "app.js", Hash: "123", PublicPath: a.PublicPath}, false)
+	if a.IsEmpty() {
+		t.Errorf("Mapper with asset should not be empty\n")
+	}
+}
+
+func TestHashIncludesPath(t *testing.T) {
+	a := NewAssetMapper()
+	read := func(path string) string {
+		asset, err := a.readAsset(strings.NewReader("body { color: red; }"), path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return asset.Hash
+	}
+
+	contentOnly := read("css/app.css")
+	if contentOnly != read("css/other.css") {
+		t.Errorf("Hash should depend only on content by default\n")
+	}
+
+	a.HashIncludesPath = true
+	unix := read("css/app.css")
+	if unix == contentOnly || unix == read("css/other.css") {
+		t.Errorf("Hash should depend on path with HashIncludesPath\n")
+	}
+	if windows := read(`css\app.css`); windows != unix {
+		t.Errorf("Hash should not depend on path separator. Expected: %s\nGot:%s\n", unix, windows)
+	}
+}
+
+func TestIntegrityCrossOrigin(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.Integrity = IntegrityCrossOrigin
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := a.ScriptTag("app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(tag), "integrity") || strings.Contains(string(tag), "crossorigin") {
+		t.Errorf("Same origin asset should not have SRI attributes. Got: %s\n", tag)
+	}
+
+	cdn := a.WithPublicPath("https://cdn.example.com/")
+	tag, err = cdn.ScriptTag("app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("console.log(1)"))
+	expected := `<script crossorigin="anonymous" integrity="sha256-` + base64.StdEncoding.EncodeToString(sum[:]) +
+		`" src="https://cdn.example.com/app.js?v=` + sha256Prefix("console.log(1)", 10) + `"></script>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err = cdn.ScriptTag("app.js", "crossorigin", "use-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(tag), `crossorigin="use-credentials"`) {
+		t.Errorf("Passed crossorigin should take precedence. Got: %s\n", tag)
+	}
+
+	cdn.Origin = "https://cdn.example.com"
+	tag, err = cdn.ScriptTag("app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(tag), "integrity") {
+		t.Errorf("Asset on page origin should not have SRI attributes. Got: %s\n", tag)
+	}
+}
+
+func TestStripScanRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "assets")
+	if err := os.MkdirAll(filepath.Join(root, "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "css", "app.css"), []byte("body {}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	a.StripScanRoot = true
+	if err := a.ScanDir(root); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/css/app.css?v=" + sha256Prefix("body {}", 10)
+	result := a.Get("css/app.css")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestTurboTrack(t *testing.T) {
+	a := NewAssetMapper()
+	a.TurboTrack = true
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app-CKgRTByK.js")
+	entry.Add("/assets/app-o2N34dPp.css")
+
+	links, err := a.CSSLinkTagsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<link data-turbo-track="reload" href="/assets/app-o2N34dPp.css" rel="stylesheet"/>`
+	if len(links) != 1 || string(links[0]) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%v\n", expected, links)
+	}
+
+	scripts, err := a.JSScriptTagsFromEntry("app", "defer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<script data-turbo-track="reload" defer src="/assets/app-CKgRTByK.js"></script>`
+	if len(scripts) != 1 || string(scripts[0]) != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%v\n", expected, scripts)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+	a.Assets["logo.svg"] = &Asset{Path: "logo.svg", Hash: "456", PublicPath: "/"}
+
+	result := a.GetMany("app.js", "logo.svg", "missing.png")
+	expected := map[string]string{
+		"app.js":      "/app.js?v=123",
+		"logo.svg":    "/logo.svg?v=456",
+		"missing.png": "missing.png",
+	}
+	if !maps.Equal(expected, result) {
+		t.Errorf("Map should be equal. Expected: %v\nGot:%v\n", expected, result)
+	}
+}
+
+func TestCSSMedia(t *testing.T) {
+	a := NewAssetMapper()
+	entry := a.CreateEntry("app")
+	entry.Add("/assets/app-o2N34dPp.css")
+	entry.Add("/assets/print.css")
+	entry.Add("/assets/print-B7PI925R.css")
+
+	links, err := a.CSSLinkTagsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`<link href="/assets/app-o2N34dPp.css" rel="stylesheet"/>`,
+		`<link href="/assets/print.css" rel="stylesheet"/>`,
+		`<link href="/assets/print-B7PI925R.css" rel="stylesheet"/>`,
+	}
+	if fmt.Sprint(links) != fmt.Sprint(expected) {
+		t.Errorf("Media should not be set by default. Expected: %v\nGot:%v\n", expected, links)
+	}
+
+	a.CSSMedia = map[string]string{"print.css": "print", "print-*.css": "print"}
+	links, err = a.CSSLinkTagsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		`<link href="/assets/app-o2N34dPp.css" rel="stylesheet"/>`,
+		`<link href="/assets/print.css" media="print" rel="stylesheet"/>`,
+		`<link href="/assets/print-B7PI925R.css" media="print" rel="stylesheet"/>`,
+	}
+	if fmt.Sprint(links) != fmt.Sprint(expected) {
+		t.Errorf("String should be equal. Expected: %v\nGot:%v\n", expected, links)
+	}
+
+	a.CSSMedia["app-*.css"] = "screen"
+	links, err = a.CSSLinkTagsFromEntry("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := string(links[0])
+	expectedLink := `<link href="/assets/app-o2N34dPp.css" media="screen" rel="stylesheet"/>`
+	if expectedLink != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expectedLink, result)
+	}
+
+	links, err = a.CSSLinkTagsFromEntry("app", "media", "all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		`<link href="/assets/app-o2N34dPp.css" media="all" rel="stylesheet"/>`,
+		`<link href="/assets/print.css" media="all" rel="stylesheet"/>`,
+		`<link href="/assets/print-B7PI925R.css" media="all" rel="stylesheet"/>`,
+	}
+	if fmt.Sprint(links) != fmt.Sprint(expected) {
+		t.Errorf("String should be equal. Expected: %v\nGot:%v\n", expected, links)
+	}
+}
+
+func TestInferExtensions(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+	a.Assets["theme.css"] = &Asset{Path: "theme.css", Hash: "456", PublicPath: "/"}
+
+	if result := a.Get("app"); result != "app" {
+		t.Errorf("Extension should not be inferred by default. Got: %s\n", result)
+	}
+
+	a.InferExtensions = true
+	cases := map[string]string{
+		"app":   "/app.js?v=123",
+		"theme": "/theme.css?v=456",
+		"other": "other",
+	}
+	for path, expected := range cases {
+		if result := a.Get(path); expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, expected, result)
+		}
+	}
+}
+
+// syntheticTree returns in-memory tree of n files spread over nested directories.
+func syntheticTree(n int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("assets/dir%d/sub%d/file%d.js", i%10, i%7, i)
+		fsys[name] = &fstest.MapFile{Data: []byte(strings.Repeat(fmt.Sprintf("console.log(%d);", i), 64))}
+	}
+	return fsys
+}
+
+func BenchmarkGet(b *testing.B) {
+	a := NewAssetMapper()
+	if err := a.ScanFS(syntheticTree(1000), "assets"); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		a.Get("dir3/sub2/file23.js")
+	}
+}
+
+// BenchmarkScanFS measures scan pipeline shared with ScanDir without disk access.
+func BenchmarkScanFS(b *testing.B) {
+	fsys := syntheticTree(1000)
+
+	b.ReportAllocs()
+	stats := ScanStats{}
+	for b.Loop() {
+		a := NewAssetMapper()
+		if err := a.ScanFS(fsys, "assets"); err != nil {
+			b.Fatal(err)
+		}
+		stats = a.Stats()
+	}
+	reportScanStats(b, stats)
+}
+
+// reportScanStats reports counters of single scan, so CI can track them next to timings.
+func reportScanStats(b *testing.B, stats ScanStats) {
+	b.ReportMetric(float64(stats.Files), "files")
+	b.ReportMetric(float64(stats.HashedBytes), "hashed-bytes")
+	b.ReportMetric(float64(stats.CacheHits), "cache-hits")
+}
+
+func BenchmarkScanDir(b *testing.B) {
+	dir := b.TempDir()
+	for name, file := range syntheticTree(200) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(path, file.Data, 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportAllocs()
+	stats := ScanStats{}
+	for b.Loop() {
+		a := NewAssetMapper()
+		a.StripScanRoot = true
+		if err := a.ScanDir(dir); err != nil {
+			b.Fatal(err)
+		}
+		stats = a.Stats()
+	}
+	reportScanStats(b, stats)
+}
+
+func BenchmarkViteManifest(b *testing.B) {
+	records := []string{}
+	for i := 0; i < 500; i++ {
+		records = append(records, fmt.Sprintf(`"src/page%d.js": {"file": "assets/page%d-CKgRTByK.js", "name": "page%d", "src": "src/page%d.js", "isEntry": true, "css": ["assets/page%d-o2N34dPp.css"]}`, i, i, i, i, i))
+	}
+	data := []byte("{" + strings.Join(records, ",") + "}")
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	entries := 0
+	for b.Loop() {
+		a := NewAssetMapper()
+		if err := a.UseManifestBytes(data, ViteManifestType); err != nil {
+			b.Fatal(err)
+		}
+		entries = len(a.Entries)
+	}
+	b.ReportMetric(float64(entries), "entries")
+}
+
+func TestSingleQuoteAttributes(t *testing.T) {
+	a := NewAssetMapper()
+	a.SingleQuoteAttributes = true
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "123", PublicPath: "/"}
+
+	tag, err := a.ScriptTag("app.js", "data-title", "Tom's app", "defer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<script data-title='Tom&#39;s app' defer src='/app.js?v=123'></script>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+}
+
+func TestMarkVolatile(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["config.js"] = &Asset{Path: "config.js", Hash: "123", PublicPath: "/"}
+	a.Assets["app.js"] = &Asset{Path: "app.js", Hash: "456", PublicPath: "/"}
+
+	a.MarkVolatile("config.js")
+
+	first, second := a.Get("config.js"), a.Get("config.js")
+	if first == second {
+		t.Errorf("Volatile asset url should change between calls. Got: %s\n", first)
+	}
+	if !strings.HasPrefix(first, "/config.js?v=") || first == "/config.js?v=123" {
+		t.Errorf("Volatile asset should get unique version instead of hash. Got: %s\n", first)
+	}
+	if a.Get("app.js") != a.Get("app.js") {
+		t.Errorf("Other assets should keep stable url\n")
+	}
+}
+
+func TestIntegrityRewriteCSSURLs(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"app.js": "console.log(1)", "app.css": "body { background: url(bg.png); }", "bg.png": "png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.Integrity = IntegrityAlways
+	a.RewriteCSSURLs = true
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// served css differs from scanned file, so its digest would block the stylesheet
+	tag, err := a.LinkTag("app.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<link href="/app.css?v=` + sha256Prefix("body { background: url(bg.png); }", 10) + `" rel="stylesheet"/>`
+	if expected != string(tag) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, tag)
+	}
+
+	tag, err = a.ScriptTag("app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(tag), "integrity=") {
+		t.Errorf("Script should keep SRI attributes. Got: %s\n", tag)
+	}
+}
+
+func TestScanStats(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"app.js": "app", "style.css": "style"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.HashCache = true
+	a.HashCacheDir = t.TempDir()
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ScanDirReplace(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ScanStats{Files: 4, HashedFiles: 2, HashedBytes: 8, CacheHits: 2}
+	if result := a.Stats(); expected != result {
+		t.Errorf("Unexpected stats. Expected: %+v\nGot:%+v\n", expected, result)
+	}
 }
@@ -0,0 +1,37 @@
+package asset
+
+import "encoding/base64"
+
+// CSPHashes returns hash sources of mapped js and css files for Content-Security-Policy
+// script-src and style-src directives, sorted by asset path.
+//
+// CSP hash source is base64 encoded sha256 of file content wrapped in single quotes and prefixed
+// with algorithm, e.g. 'sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU='. Subresource Integrity
+// uses the same digest without quotes in integrity attribute. Browsers match hash sources against
+// external files only when the tag has integrity attribute, so both are usually needed.
+//
+// Only assets hashed during scan have content digest, assets loaded from manifest are skipped.
+// With [AssetMapper.RewriteCSSURLs] css files are served rewritten, so their digest doesn't match
+// and they are skipped as well.
+//
+// Example:
+//
+//	scriptSrc, styleSrc := assetMapper.CSPHashes()
+//	csp := "script-src 'self' " + strings.Join(scriptSrc, " ") + "; style-src 'self' " + strings.Join(styleSrc, " ")
+func (a *AssetMapper) CSPHashes() (scriptSrc, styleSrc []string) {
+	for _, asset := range a.uniqueAssets() {
+		if asset.digest == nil || a.rewritten(asset) {
+			continue
+		}
+
+		source := "'sha256-" + base64.StdEncoding.EncodeToString(asset.digest) + "'"
+		switch kindOf(asset.Path) {
+		case KindJS:
+			scriptSrc = append(scriptSrc, source)
+		case KindCSS:
+			styleSrc = append(styleSrc, source)
+		}
+	}
+
+	return scriptSrc, styleSrc
+}
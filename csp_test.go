@@ -0,0 +1,70 @@
+package asset
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestCSPHashes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app.js":    "console.log(1)",
+		"style.css": "body { color: red; }",
+		"logo.png":  "png",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	scriptSrc, styleSrc := a.CSPHashes()
+	if len(scriptSrc) != 1 || len(styleSrc) != 1 {
+		t.Fatalf("Expected one script and one style hash. Got: %v %v\n", scriptSrc, styleSrc)
+	}
+
+	format := regexp.MustCompile(`^'sha256-[A-Za-z0-9+/]{43}='$`)
+	for _, source := range append(scriptSrc, styleSrc...) {
+		if !format.MatchString(source) {
+			t.Errorf("Hash source has invalid format: %s\n", source)
+		}
+	}
+
+	sum := sha256.Sum256([]byte("console.log(1)"))
+	expected := "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+	if expected != scriptSrc[0] {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, scriptSrc[0])
+	}
+}
+
+func TestCSPHashesRewriteCSSURLs(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"app.js": "console.log(1)", "style.css": "body { color: red; }"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.RewriteCSSURLs = true
+	a.Integrity = IntegrityAlways
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	scriptSrc, styleSrc := a.CSPHashes()
+	if len(scriptSrc) != 1 || len(styleSrc) != 0 {
+		t.Errorf("Expected only script hash for rewritten css. Got: %v %v\n", scriptSrc, styleSrc)
+	}
+}
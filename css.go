@@ -0,0 +1,175 @@
+package asset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	cssImportRe = regexp.MustCompile(`@import\s+(?:url\(\s*)?["']?([^"')\s;]+)`)
+	cssURLRe    = regexp.MustCompile(`url\(\s*(["']?)([^"')\s]+)["']?\s*\)`)
+	// html end tags are case insensitive, so any of them closes inlined style element
+	styleEndRe = regexp.MustCompile(`(?i)</(style)`)
+	// cssStringReplacer escapes url for single quoted css string inside of <style> element
+	cssStringReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "<", `\3c `, "\n", `\a `)
+)
+
+// foldCSSImports updates hash of every css file with hashes of files it imports.
+// files maps file name in fsys to its asset. Imports outside of files are ignored.
+func foldCSSImports(fsys fs.FS, files map[string]*Asset) error {
+	folded := map[string]string{}
+	visiting := map[string]bool{}
+
+	var resolve func(path string) (string, error)
+	resolve = func(path string) (string, error) {
+		asset := files[path]
+		if hash, ok := folded[path]; ok {
+			return hash, nil
+		}
+		// import cycle, use own hash
+		if visiting[path] || !isCSS(path) || asset.Hash == "" {
+			return asset.Hash, nil
+		}
+		visiting[path] = true
+
+		imports, err := cssImports(fsys, path)
+		if err != nil {
+			return "", err
+		}
+
+		hasher := sha256.New()
+		hasher.Write([]byte(asset.Hash))
+		deps := 0
+		for _, imported := range imports {
+			if _, ok := files[imported]; !ok {
+				continue
+			}
+
+			hash, err := resolve(imported)
+			if err != nil {
+				return "", err
+			}
+			hasher.Write([]byte(hash))
+			deps++
+		}
+
+		hash := asset.Hash
+		if deps > 0 {
+			hash = hex.EncodeToString(hasher.Sum(nil))[:len(asset.Hash)]
+		}
+		folded[path] = hash
+
+		return hash, nil
+	}
+
+	for path := range files {
+		if !isCSS(path) {
+			continue
+		}
+
+		hash, err := resolve(path)
+		if err != nil {
+			return err
+		}
+		files[path].Hash = hash
+	}
+
+	return nil
+}
+
+// cssImports returns names of local files imported by css file name in fsys.
+func cssImports(fsys fs.FS, name string) ([]string, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := []string{}
+	for _, match := range cssImportRe.FindAllStringSubmatch(string(content), -1) {
+		ref := match[1]
+		if strings.Contains(ref, ":") || strings.HasPrefix(ref, "/") {
+			continue
+		}
+
+		imports = append(imports, path.Join(path.Dir(name), ref))
+	}
+
+	return imports, nil
+}
+
+// InlineEntryCSS reads css files of entry from fsys and returns them as single <style> block,
+// useful to inline small critical stylesheets. fsys root should match PublicPath, e.g.
+// os.DirFS("public") for assets served from "public" directory.
+//
+// It isn't part of [AssetMapper.TemplateFuncs], because it needs fsys. Example registering it
+// with fsys bound:
+//
+//	funcs := assetMapper.TemplateFuncs()
+//	funcs["inlineEntryCss"] = func(name string) (template.HTML, error) {
+//		return assetMapper.InlineEntryCSS(name, os.DirFS("public"))
+//	}
+func (a *AssetMapper) InlineEntryCSS(name string, fsys fs.FS) (template.HTML, error) {
+	styles := []string{}
+	for _, css := range a.CSSEntry(name) {
+		content, err := fs.ReadFile(fsys, a.publicFile(css))
+		if err != nil {
+			return "", err
+		}
+		styles = append(styles, string(content))
+	}
+
+	// css can't contain closing tag, otherwise rest of it would be parsed as html
+	style := styleEndRe.ReplaceAllString(strings.Join(styles, "\n"), `<\/$1`)
+
+	return template.HTML("<style>" + style + "</style>"), nil
+}
+
+// InlineImportStyle returns single <style> block importing all css files of entry, e.g.
+// <style>@import url('/assets/app-o2N34dPp.css');</style>. It's compatibility helper for
+// environments which can't render several link tags, [AssetMapper.CSSLinkTagsFromEntry]
+// should be preferred, because imports are loaded sequentially.
+//
+// Example usage in template:
+//
+//	{{ inlineImportStyle "app" }}
+func (a *AssetMapper) InlineImportStyle(name string) template.HTML {
+	imports := strings.Builder{}
+	for _, css := range a.CSSEntry(name) {
+		imports.WriteString("@import url('" + cssStringReplacer.Replace(css) + "');")
+	}
+
+	return template.HTML("<style>" + imports.String() + "</style>")
+}
+
+// publicFile returns file path relative to public root for asset url.
+func (a *AssetMapper) publicFile(assetURL string) string {
+	if u, err := url.Parse(assetURL); err == nil {
+		assetURL = u.Path
+	}
+	return strings.TrimLeft(strings.TrimPrefix(assetURL, a.PublicPathPrefix()), "/")
+}
+
+// rewriteCSSURLs replaces relative url() references in css content of file with urls of mapped
+// assets. References which are not mapped, absolute or data urls are kept.
+func (a *AssetMapper) rewriteCSSURLs(content, file string) string {
+	return cssURLRe.ReplaceAllStringFunc(content, func(match string) string {
+		groups := cssURLRe.FindStringSubmatch(match)
+		quote, ref := groups[1], groups[2]
+		if strings.Contains(ref, ":") || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") {
+			return match
+		}
+
+		asset, ok := a.lookup(fileKey(path.Join(path.Dir(file), ref)))
+		if !ok {
+			return match
+		}
+
+		return "url(" + quote + a.publicURL(asset.String()) + quote + ")"
+	})
+}
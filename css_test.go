@@ -0,0 +1,89 @@
+package asset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCSSImportHashing(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scan := func(importHashing bool) *AssetMapper {
+		a := NewAssetMapper()
+		a.Trim = dir + "/"
+		a.CSSImportHashing = importHashing
+		if err := a.ScanDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	write("app.css", `@import url("base.css"); @import 'https://fonts.example.com/font.css'; body { margin: 0; }`)
+	write("base.css", "body { color: red; }")
+
+	plain := scan(false).Assets["app.css"].Hash
+	before := scan(true).Assets["app.css"].Hash
+
+	write("base.css", "body { color: blue; }")
+
+	if scan(false).Assets["app.css"].Hash != plain {
+		t.Errorf("Importer hash should not change without CSSImportHashing\n")
+	}
+	if scan(true).Assets["app.css"].Hash == before {
+		t.Errorf("Importer hash should change after imported file change\n")
+	}
+}
+
+func TestInlineEntryCSS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/base-B7PI925R.css": {Data: []byte("body { margin: 0; }")},
+		"assets/app-o2N34dPp.css":  {Data: []byte(".app::after { content: '</style>'; }\n.app::before { content: '</STYLE>'; }")},
+	}
+
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	entry := a.CreateEntry("app")
+	entry.Add("/static/assets/base-B7PI925R.css")
+	entry.Add("/static/assets/app-o2N34dPp.css")
+
+	result, err := a.InlineEntryCSS("app", fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "<style>body { margin: 0; }\n.app::after { content: '<\\/style>'; }\n.app::before { content: '<\\/STYLE>'; }</style>"
+	if expected != string(result) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	entry.Add("/static/assets/missing.css")
+	if _, err := a.InlineEntryCSS("app", fsys); err == nil {
+		t.Errorf("Missing css file should return error\n")
+	}
+}
+
+func TestInlineImportStyle(t *testing.T) {
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	entry := a.CreateEntry("app")
+	entry.Add("/static/assets/app-CKgRTByK.js")
+	entry.Add("/static/assets/base-B7PI925R.css")
+	entry.Add("/static/assets/it's-o2N34dPp.css")
+
+	result := string(a.InlineImportStyle("app"))
+	expected := `<style>@import url('/static/assets/base-B7PI925R.css');@import url('/static/assets/it\'s-o2N34dPp.css');</style>`
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	expected = "<style></style>"
+	if result := string(a.InlineImportStyle("missing")); expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
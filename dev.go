@@ -0,0 +1,54 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+const reactRefreshPreamble = `<script type="module">
+import RefreshRuntime from %s
+RefreshRuntime.injectIntoGlobalHook(window)
+window.$RefreshReg$ = () => {}
+window.$RefreshSig$ = () => (type) => type
+window.__vite_plugin_react_preamble_installed__ = true
+</script>`
+
+func (a *AssetMapper) devURL(path string) string {
+	if a.ViteDevServer == "" || isAbsoluteURL(path) {
+		return path
+	}
+	return joinPath(a.ViteDevServer, path)
+}
+
+// ViteClientTag returns scripts required by the Vite dev server: the @vite/client
+// module and, if ReactRefresh is set, the @react-refresh preamble.
+// Outside of DevMode it returns empty HTML, so it can stay in the layout template.
+//
+// Example usage in template:
+//
+//	{{ viteClientTag }}
+//	{{ scriptTag "src/app.js" }}
+//
+// Result in DevMode:
+//
+//	<script src="http://localhost:5173/@vite/client" type="module"></script>
+//	<script src="http://localhost:5173/src/app.js" type="module"></script>
+func (a *AssetMapper) ViteClientTag() template.HTML {
+	if !a.DevMode {
+		return ""
+	}
+
+	tags := []string{}
+	if a.ReactRefresh {
+		// json encoding gives valid js string literal safe to put inside script tag
+		origin, _ := json.Marshal(a.devURL("@react-refresh"))
+		tags = append(tags, fmt.Sprintf(reactRefreshPreamble, origin))
+	}
+
+	client := a.attributes(map[string]string{"src": a.devURL("@vite/client"), "type": "module"})
+	tags = append(tags, string(scriptTag(client)))
+
+	return template.HTML(strings.Join(tags, "\n"))
+}
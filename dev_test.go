@@ -0,0 +1,55 @@
+package asset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDevModeUsesViteDevServer(t *testing.T) {
+	a := NewAssetMapper()
+	a.DevMode = true
+	a.ViteDevServer = "http://127.0.0.1:3000/"
+
+	result := a.Get("/src/app.js")
+	expected := "http://127.0.0.1:3000/src/app.js"
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	tag, err := a.ScriptTag("src/app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(tag), `src="http://127.0.0.1:3000/src/app.js"`) || !strings.Contains(string(tag), `type="module"`) {
+		t.Errorf("Script tag should point to dev server module. Got: %s\n", tag)
+	}
+
+	client := string(a.ViteClientTag())
+	expected = `<script src="http://127.0.0.1:3000/@vite/client" type="module"></script>`
+	if expected != client {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, client)
+	}
+
+	a.SingleQuoteAttributes = true
+	client = string(a.ViteClientTag())
+	expected = `<script src='http://127.0.0.1:3000/@vite/client' type='module'></script>`
+	if expected != client {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, client)
+	}
+	a.SingleQuoteAttributes = false
+
+	a.ReactRefresh = true
+	client = string(a.ViteClientTag())
+	if !strings.Contains(client, `import RefreshRuntime from "http://127.0.0.1:3000/@react-refresh"`) {
+		t.Errorf("React refresh preamble should use configured origin. Got: %s\n", client)
+	}
+}
+
+func TestViteClientTagOutsideDevMode(t *testing.T) {
+	a := NewAssetMapper()
+	a.ReactRefresh = true
+
+	if tag := a.ViteClientTag(); tag != "" {
+		t.Errorf("Vite client tag should be empty outside dev mode. Got: %s\n", tag)
+	}
+}
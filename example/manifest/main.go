@@ -51,7 +51,7 @@ func main() {
 			log.Fatal(err)
 		}
 	})
-	http.Handle("GET /static/", http.StripPrefix("/static", http.FileServer(http.Dir("./public"))))
+	http.Handle("GET "+assetMapper.PublicPathPrefix(), assetMapper.FileServer(http.Dir("./public")))
 
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
@@ -0,0 +1,52 @@
+package asset
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type exportedAsset struct {
+	PublicPath string `json:"publicPath"`
+	Hash       string `json:"hash"`
+}
+
+// ExportJSON writes compact JSON object of asset path to its public url and hash. Keys are sorted,
+// so output is stable and can be compared with build tool manifest in CI to detect drift.
+//
+// Example output:
+//
+//	{"css/app.css":{"publicPath":"/css/app.css?v=0a1b2c3d4e","hash":"0a1b2c3d4e"}}
+func (a *AssetMapper) ExportJSON(w io.Writer) error {
+	assets := map[string]exportedAsset{}
+	for _, asset := range a.uniqueAssets() {
+		assets[asset.Path] = exportedAsset{
+			PublicPath: asset.String(),
+			Hash:       asset.Hash,
+		}
+	}
+
+	return json.NewEncoder(w).Encode(assets)
+}
+
+type exportedEntry struct {
+	CSS []string `json:"css"`
+	JS  []string `json:"js"`
+}
+
+// WriteEntriesJSON writes JSON object of entry name to its css and js public urls, so frontend
+// code can resolve entries at runtime.
+//
+// Example output:
+//
+//	{"app":{"css":["/assets/app-o2N34dPp.css"],"js":["/assets/app-CKgRTByK.js"]}}
+func (a *AssetMapper) WriteEntriesJSON(w io.Writer) error {
+	entries := map[string]exportedEntry{}
+	for _, name := range a.EntryNames() {
+		entries[name] = exportedEntry{
+			CSS: a.CSSEntry(name),
+			JS:  a.JSEntry(name),
+		}
+	}
+
+	return json.NewEncoder(w).Encode(entries)
+}
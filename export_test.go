@@ -0,0 +1,58 @@
+package asset
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportJSON(t *testing.T) {
+	a := NewAssetMapper()
+	a.AddAsset(&Asset{Path: "js/app.js", File: "js/app.js", Hash: "456", PublicPath: a.PublicPath}, false)
+	a.AddAsset(&Asset{Path: "css/app.css", File: "css/app.css", Hash: "123", PublicPath: a.PublicPath}, false)
+	a.AddAsset(&Asset{Path: "src/main.js", File: "assets/main-CKgRTByK.js", PublicPath: a.PublicPath, Fingerprinted: true}, false)
+
+	expected := `{"css/app.css":{"publicPath":"/css/app.css?v=123","hash":"123"},` +
+		`"js/app.js":{"publicPath":"/js/app.js?v=456","hash":"456"},` +
+		`"src/main.js":{"publicPath":"/assets/main-CKgRTByK.js","hash":""}}` + "\n"
+
+	for i := 0; i < 5; i++ {
+		var buf bytes.Buffer
+		if err := a.ExportJSON(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected != buf.String() {
+			t.Fatalf("String should be equal. Expected: %s\nGot:%s\n", expected, buf.String())
+		}
+	}
+}
+
+func TestWriteEntriesJSON(t *testing.T) {
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	if err := a.UseManifestBytes([]byte(multiEntryViteManifest), ViteManifestType); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := a.WriteEntriesJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := map[string]exportedEntry{}
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(a.Entries) {
+		t.Fatalf("All entries should be exported. Got: %v\n", entries)
+	}
+	for name := range a.Entries {
+		if strings.Join(entries[name].CSS, ",") != strings.Join(a.CSSEntry(name), ",") ||
+			strings.Join(entries[name].JS, ",") != strings.Join(a.JSEntry(name), ",") {
+			t.Errorf("Exported entry %s should match mapper. Got: %v\n", name, entries[name])
+		}
+	}
+}
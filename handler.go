@@ -0,0 +1,195 @@
+package asset
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	defaultCacheControl   = "public, max-age=3600"
+)
+
+// FileServer returns handler serving files from root, same as [http.FileServer], but with
+// Cache-Control header set for mapped assets. Fingerprinted assets (loaded from manifest)
+// are marked immutable, other mapped assets get shorter max-age, because their URL stays
+// the same after change.
+//
+// Mapped assets with known ModTime are served with Last-Modified set to it, so
+// If-Modified-Since requests are answered with 304 for non fingerprinted assets as well.
+//
+// Headers from [AssetMapper.KindHeaders] and [AssetMapper.Headers] are added to responses
+// of matching files, but not to not found responses.
+//
+// With [AssetMapper.RewriteCSSURLs] set, url() references in served css files are rewritten
+// to mapped asset urls. Rewritten files are served with "Cache-Control: no-cache" and validated
+// by ETag of rewritten content, so they are refreshed when referenced assets change.
+//
+// Handler strips [AssetMapper.PublicPathPrefix] itself, so it should be mounted on that prefix.
+//
+// Example:
+//
+//	assetMapper.PublicPath = "/static/"
+//	http.Handle("GET /static/", assetMapper.FileServer(http.Dir("./public")))
+func (a *AssetMapper) FileServer(root http.FileSystem) http.Handler {
+	fileServer := http.FileServer(root)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// missing files are answered by file server without asset headers
+		f, err := root.Open(r.URL.Path)
+		if err != nil {
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+		defer f.Close()
+
+		file := strings.TrimLeft(r.URL.Path, "/")
+		setHeaders(w.Header(), a.KindHeaders[kindOf(file)])
+		setHeaders(w.Header(), a.Headers[file])
+
+		modTime := time.Time{}
+		if asset, ok := a.Assets[file]; ok {
+			w.Header().Set("Cache-Control", asset.cacheControl())
+			if a.volatile[asset.Path] {
+				w.Header().Set("Cache-Control", "no-cache")
+			}
+			if asset.Path != file {
+				setHeaders(w.Header(), a.Headers[asset.Path])
+			}
+			modTime = asset.ModTime
+		}
+
+		if a.RewriteCSSURLs && isCSS(file) {
+			content, err := io.ReadAll(f)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			// rewritten content changes with referenced assets while css url stays the same,
+			// so it's revalidated by ETag on every request instead of cached by max-age
+			rewritten := a.rewriteCSSURLs(string(content), file)
+			sum := sha256.Sum256([]byte(rewritten))
+			w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeContent(w, r, r.URL.Path, time.Time{}, strings.NewReader(rewritten))
+			return
+		}
+
+		if !modTime.IsZero() {
+			http.ServeContent(w, r, r.URL.Path, modTime, f)
+			return
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+
+	return http.StripPrefix(strings.TrimSuffix(a.PublicPathPrefix(), "/"), handler)
+}
+
+// setHeaders replaces values of dst headers with values from src.
+func setHeaders(dst, src http.Header) {
+	for name, values := range src {
+		dst.Del(name)
+		for _, value := range values {
+			dst.Add(name, value)
+		}
+	}
+}
+
+func (a *Asset) cacheControl() string {
+	if a.Fingerprinted {
+		return immutableCacheControl
+	}
+	return defaultCacheControl
+}
+
+// APIHandler returns handler serving asset map as JSON object of asset path to public url,
+// so frontend code can resolve assets at runtime. Response has ETag based on its content
+// and is gzipped for clients accepting gzip encoding.
+//
+// Example:
+//
+//	http.Handle("GET /api/assets", assetMapper.APIHandler())
+func (a *AssetMapper) APIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		urls := map[string]string{}
+		for _, asset := range a.uniqueAssets() {
+			urls[asset.Path] = asset.String()
+		}
+
+		content, err := json.Marshal(urls)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// gzipped body is different representation, so it gets its own ETag
+		gzipped := acceptsGzip(r)
+		sum := sha256.Sum256(content)
+		etag := hex.EncodeToString(sum[:16])
+		if gzipped {
+			etag += "-gzip"
+		}
+		etag = `"` + etag + `"`
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		// 304 response must vary the same way as full one
+		w.Header().Add("Vary", "Accept-Encoding")
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		writeCompressed(w, content, gzipped)
+	})
+}
+
+// etagMatches reports whether If-None-Match header matches etag. Header is list of ETags or "*",
+// compared weakly, so "W/" prefix is ignored.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCompressed writes content, gzipped if requested. Caller sets "Vary: Accept-Encoding" header.
+func writeCompressed(w http.ResponseWriter, content []byte, gzipped bool) {
+	if !gzipped {
+		w.Write(content)
+		return
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	gz := gzip.NewWriter(w)
+	gz.Write(content)
+	gz.Close()
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(encoding, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
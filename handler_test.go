@@ -0,0 +1,351 @@
+package asset
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileServerCacheControl(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"assets/app-CKgRTByK.js", "favicon.ico"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	a.AddAsset(&Asset{
+		Path:          "src/app.js",
+		PublicPath:    a.PublicPath,
+		File:          "assets/app-CKgRTByK.js",
+		Fingerprinted: true,
+	}, true)
+
+	handler := a.FileServer(http.Dir(dir))
+
+	cases := map[string]string{
+		"/static/assets/app-CKgRTByK.js": immutableCacheControl,
+		"/static/favicon.ico":            defaultCacheControl,
+	}
+	for url, expected := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("Unexpected status for %s: %d\n", url, rec.Code)
+		}
+
+		result := rec.Header().Get("Cache-Control")
+		if expected != result {
+			t.Errorf("Cache-Control should be equal for %s. Expected: %s\nGot:%s\n", url, expected, result)
+		}
+	}
+}
+
+func TestFileServerHeaders(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "fonts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"fonts/inter.woff2", "app.js"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.KindHeaders = map[Kind]http.Header{
+		KindFont: {"Access-Control-Allow-Origin": {"*"}},
+	}
+	a.Headers = map[string]http.Header{
+		"app.js": {"X-Robots-Tag": {"noindex"}},
+	}
+	handler := a.FileServer(http.Dir(dir))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fonts/inter.woff2", nil))
+	if result := rec.Header().Get("Access-Control-Allow-Origin"); result != "*" {
+		t.Errorf("Font response should have CORS header. Got: %s\n", result)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app.js", nil))
+	if result := rec.Header().Get("Access-Control-Allow-Origin"); result != "" {
+		t.Errorf("Script response should not have CORS header. Got: %s\n", result)
+	}
+	if result := rec.Header().Get("X-Robots-Tag"); result != "noindex" {
+		t.Errorf("Script response should have path header. Got: %s\n", result)
+	}
+	// headers describe served file, so they are not sent with 404
+	a.Headers["missing.woff2"] = http.Header{"X-Robots-Tag": {"noindex"}}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing.woff2", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Missing file should return 404. Got: %d\n", rec.Code)
+	}
+	for _, name := range []string{"Access-Control-Allow-Origin", "X-Robots-Tag"} {
+		if result := rec.Header().Get(name); result != "" {
+			t.Errorf("Not found response should not have %s header. Got: %s\n", name, result)
+		}
+	}
+}
+
+func TestFileServerRewriteCSSURLs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"logo.png":    "png",
+		"css/app.css": `.logo { background: url(../logo.png); } .icon { background: url("data:image/png;base64,AAAA"); }`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	a.Trim = dir + "/"
+	a.RewriteCSSURLs = true
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	a.FileServer(http.Dir(dir)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/css/app.css", nil))
+
+	expected := `.logo { background: url(/static/logo.png?v=` + sha256Prefix("png", 10) + `); } .icon { background: url("data:image/png;base64,AAAA"); }`
+	if expected != rec.Body.String() {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, rec.Body.String())
+	}
+	if result := rec.Header().Get("Content-Type"); !strings.HasPrefix(result, "text/css") {
+		t.Errorf("Unexpected Content-Type: %s\n", result)
+	}
+	if result := rec.Header().Get("Cache-Control"); result != "no-cache" {
+		t.Errorf("Rewritten css should be revalidated. Got Cache-Control: %s\n", result)
+	}
+}
+
+func TestFileServerRewriteCSSURLsRevalidation(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("logo.png", "png")
+	write("app.css", ".logo { background: url(logo.png); }")
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.RewriteCSSURLs = true
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	handler := a.FileServer(http.Dir(dir))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app.css", nil))
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Rewritten css should have ETag")
+	}
+
+	write("logo.png", "png changed")
+	if err := a.ScanDirReplace(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/app.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	req.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Css referencing changed asset should be served again. Got status: %d\n", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/app.css", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Unchanged css should not be served again. Got status: %d\n", rec.Code)
+	}
+}
+
+func TestFileServerIfModifiedSince(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "favicon.ico"), []byte("icon"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := a.Assets["favicon.ico"].ModTime
+	if modTime.IsZero() {
+		t.Fatal("ModTime should be populated during scan")
+	}
+
+	handler := a.FileServer(http.Dir(dir))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	if rec.Header().Get("Last-Modified") != modTime.UTC().Format(http.TimeFormat) {
+		t.Errorf("Last-Modified should match asset ModTime. Got: %s\n", rec.Header().Get("Last-Modified"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/favicon.ico", nil)
+	req.Header.Set("If-Modified-Since", modTime.Add(time.Hour).UTC().Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for If-Modified-Since newer than ModTime. Got: %d\n", rec.Code)
+	}
+}
+
+func TestAPIHandler(t *testing.T) {
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	a.AddAsset(&Asset{Path: "css/app.css", File: "css/app.css", Hash: "123", PublicPath: a.PublicPath}, false)
+	a.AddAsset(&Asset{Path: "src/app.js", File: "assets/app-CKgRTByK.js", PublicPath: a.PublicPath, Fingerprinted: true}, false)
+
+	handler := a.APIHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/assets", nil))
+
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected content type: %s\n", rec.Header().Get("Content-Type"))
+	}
+
+	var urls map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &urls); err != nil {
+		t.Fatalf("Response should be valid json: %v", err)
+	}
+
+	expected := map[string]string{
+		"css/app.css": "/static/css/app.css?v=123",
+		"src/app.js":  "/static/assets/app-CKgRTByK.js",
+	}
+	if len(urls) != len(expected) {
+		t.Errorf("Unexpected asset map: %v\n", urls)
+	}
+	for path, url := range expected {
+		if urls[path] != url {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, url, urls[path])
+		}
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("ETag should be set")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/assets", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for matching ETag. Got: %d\n", rec.Code)
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Vary should be set on 304 response. Got: %q\n", rec.Header().Get("Vary"))
+	}
+
+	for ifNoneMatch, expected := range map[string]int{
+		`"other", ` + etag:                       http.StatusNotModified,
+		"W/" + etag:                              http.StatusNotModified,
+		"*":                                      http.StatusNotModified,
+		`"other"`:                                http.StatusOK,
+		strings.TrimSuffix(etag, `"`) + `-gzip"`: http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/assets", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != expected {
+			t.Errorf("Unexpected status for If-None-Match %s. Expected: %d\nGot:%d\n", ifNoneMatch, expected, rec.Code)
+		}
+	}
+
+	a.AddAsset(&Asset{Path: "new.js", File: "new.js", Hash: "456", PublicPath: a.PublicPath}, false)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/assets", nil))
+	if rec.Header().Get("ETag") == etag {
+		t.Errorf("ETag should change when asset map changes\n")
+	}
+}
+
+func TestAPIHandlerGzip(t *testing.T) {
+	a := NewAssetMapper()
+	a.AddAsset(&Asset{Path: "css/app.css", File: "css/app.css", Hash: "123", PublicPath: a.PublicPath}, false)
+
+	handler := a.APIHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/assets", nil)
+	req.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Response should be gzipped. Got encoding: %q\n", rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Vary should be set. Got: %q\n", rec.Header().Get("Vary"))
+	}
+	etag := rec.Header().Get("ETag")
+	if !strings.HasSuffix(etag, `-gzip"`) {
+		t.Errorf("Gzipped response should have its own ETag. Got: %s\n", etag)
+	}
+
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"css/app.css":"/css/app.css?v=123"}`
+	if expected != string(content) {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, content)
+	}
+
+	for _, acceptEncoding := range []string{"", "br", "gzip;q=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/assets", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != expected {
+			t.Errorf("Response should not be gzipped for %q\n", acceptEncoding)
+		}
+		if rec.Header().Get("ETag") == etag {
+			t.Errorf("Identity response should not share ETag of gzipped one for %q\n", acceptEncoding)
+		}
+	}
+}
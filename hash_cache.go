@@ -0,0 +1,115 @@
+package asset
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// hashCacheFile is name of sidecar file older versions wrote inside scan root
+	hashCacheFile    = ".asset-hashes.json"
+	hashCacheVersion = 3
+)
+
+// hashCache stores file hashes of scanned directory between restarts in sidecar file.
+// Record is reused only if file size and modification time didn't change.
+type hashCache struct {
+	path    string
+	options string
+	records map[string]hashCacheRecord
+	seen    map[string]hashCacheRecord
+}
+
+type hashCacheFileData struct {
+	Version int `json:"version"`
+	// hashing options records were computed with, look [AssetMapper.hashOptions]
+	Options string                     `json:"options"`
+	Files   map[string]hashCacheRecord `json:"files"`
+}
+
+type hashCacheRecord struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"`
+	Hash    string `json:"hash"`
+	// sha256 of raw content, used for integrity attributes and CSP hashes
+	Digest      []byte `json:"digest"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+}
+
+// loadHashCache reads sidecar file from path. Missing, broken or outdated sidecar, or sidecar
+// written with different hashing options results in empty cache.
+func loadHashCache(path, options string) *hashCache {
+	c := &hashCache{
+		path:    path,
+		options: options,
+		records: map[string]hashCacheRecord{},
+		seen:    map[string]hashCacheRecord{},
+	}
+
+	content, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+
+	var data hashCacheFileData
+	if err := json.Unmarshal(content, &data); err != nil || data.Version != hashCacheVersion || data.Options != options {
+		return c
+	}
+	if data.Files != nil {
+		c.records = data.Files
+	}
+
+	return c
+}
+
+// get returns cached record of file, if file didn't change and hash has expected length.
+func (c *hashCache) get(name string, info fs.FileInfo, hashLen int) (hashCacheRecord, bool) {
+	record, ok := c.records[name]
+	if !ok || record.Size != info.Size() || record.ModTime != info.ModTime().UnixNano() || len(record.Hash) != hashLen {
+		return hashCacheRecord{}, false
+	}
+
+	c.seen[name] = record
+	return record, true
+}
+
+// keep carries record of file skipped by scan over to saved sidecar, if file didn't change.
+func (c *hashCache) keep(name string, info fs.FileInfo) {
+	record, ok := c.records[name]
+	if ok && record.Size == info.Size() && record.ModTime == info.ModTime().UnixNano() {
+		c.seen[name] = record
+	}
+}
+
+func (c *hashCache) set(name string, info fs.FileInfo, asset *Asset) {
+	c.seen[name] = hashCacheRecord{
+		Size:        info.Size(),
+		ModTime:     info.ModTime().UnixNano(),
+		Hash:        asset.Hash,
+		Digest:      asset.digest,
+		ContentType: asset.ContentType,
+		Width:       asset.Width,
+		Height:      asset.Height,
+	}
+}
+
+// save writes records of files seen during scan, so removed files are dropped from sidecar.
+func (c *hashCache) save() error {
+	content, err := json.MarshalIndent(hashCacheFileData{
+		Version: hashCacheVersion,
+		Options: c.options,
+		Files:   c.seen,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, content, 0o644)
+}
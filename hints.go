@@ -0,0 +1,87 @@
+package asset
+
+import (
+	"html/template"
+	"slices"
+)
+
+// HeadLinks returns Link header values for css and js files, images and fonts of entries as preloads
+// and lazily loaded chunks as prefetches, e.g. to send them with 103 Early Hints. Urls shared by several
+// entries are listed once, and prefetches don't repeat preloaded urls.
+//
+// Example:
+//
+//	preloads, prefetches := assetMapper.HeadLinks("app", "admin")
+//	for _, link := range append(preloads, prefetches...) {
+//		w.Header().Add("Link", link)
+//	}
+//	w.WriteHeader(http.StatusEarlyHints)
+func (a *AssetMapper) HeadLinks(entries ...string) (preloads, prefetches []string) {
+	seen := map[string]bool{}
+	link := func(u, params string) string {
+		seen[u] = true
+		return "<" + u + ">; " + params
+	}
+
+	for _, name := range entries {
+		for _, css := range a.CSSEntry(name) {
+			if !seen[css] {
+				preloads = append(preloads, link(css, "rel=preload; as=style"))
+			}
+		}
+		for _, js := range a.JSEntry(name) {
+			if !seen[js] {
+				preloads = append(preloads, link(js, "rel=preload; as=script"))
+			}
+		}
+		if entry, ok := a.Entries[name]; ok {
+			for _, u := range a.publicURLs(entry.Assets) {
+				if seen[u] {
+					continue
+				}
+				switch kindOf(u) {
+				case KindImage:
+					preloads = append(preloads, link(u, "rel=preload; as=image"))
+				case KindFont:
+					preloads = append(preloads, link(u, "rel=preload; as=font; crossorigin"))
+				}
+			}
+		}
+	}
+
+	for _, name := range entries {
+		entry, ok := a.Entries[name]
+		if !ok {
+			continue
+		}
+		for _, u := range a.publicURLs(entry.Prefetch) {
+			if !seen[u] {
+				prefetches = append(prefetches, link(u, "rel=prefetch"))
+			}
+		}
+	}
+
+	return preloads, prefetches
+}
+
+// PrefetchEntryTags returns link tags with rel="prefetch" for css and js files of entry, so browser
+// can fetch assets of likely next page during idle time. Unlike preload, prefetched files are not
+// needed by current page.
+//
+// Example usage in template:
+//
+//	{{ range (entryPrefetch "checkout") }}
+//		{{ . }}
+//	{{ end }}
+//
+// Result:
+//
+//	<link href="/assets/checkout-o2N34dPp.css" rel="prefetch"/>
+//	<link href="/assets/checkout-Dq1cWbUa.js" rel="prefetch"/>
+func (a *AssetMapper) PrefetchEntryTags(name string) ([]template.HTML, error) {
+	result := []template.HTML{}
+	for _, u := range slices.Concat(a.CSSEntry(name), a.JSEntry(name)) {
+		result = append(result, linkTag(a.attributes(map[string]string{"href": u, "rel": "prefetch"})))
+	}
+	return result, nil
+}
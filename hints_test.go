@@ -0,0 +1,69 @@
+package asset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadLinks(t *testing.T) {
+	a := NewAssetMapper()
+	err := a.UseManifestBytes([]byte(`{
+  "src/app.js": {
+    "file": "assets/app-CKgRTByK.js",
+    "name": "app",
+    "isEntry": true,
+    "css": ["assets/shared-o2N34dPp.css"],
+    "dynamicImports": ["src/settings.js"]
+  },
+  "src/admin.js": {
+    "file": "assets/admin-Dq1cWbUa.js",
+    "name": "admin",
+    "isEntry": true,
+    "css": ["assets/shared-o2N34dPp.css"],
+    "dynamicImports": ["src/settings.js", "src/app.js"]
+  },
+  "src/settings.js": {
+    "file": "assets/settings-B7PI925R.js",
+    "isDynamicEntry": true
+  }
+}`), ViteManifestType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preloads, prefetches := a.HeadLinks("app", "admin")
+
+	expected := []string{
+		"</assets/shared-o2N34dPp.css>; rel=preload; as=style",
+		"</assets/app-CKgRTByK.js>; rel=preload; as=script",
+		"</assets/admin-Dq1cWbUa.js>; rel=preload; as=script",
+	}
+	if strings.Join(expected, ",") != strings.Join(preloads, ",") {
+		t.Errorf("Unexpected preloads. Expected: %v\nGot:%v\n", expected, preloads)
+	}
+
+	expected = []string{"</assets/settings-B7PI925R.js>; rel=prefetch"}
+	if strings.Join(expected, ",") != strings.Join(prefetches, ",") {
+		t.Errorf("Unexpected prefetches. Expected: %v\nGot:%v\n", expected, prefetches)
+	}
+}
+
+func TestPrefetchEntryTags(t *testing.T) {
+	a := NewAssetMapper()
+	entry := a.CreateEntry("checkout")
+	entry.Add("/assets/checkout-Dq1cWbUa.js")
+	entry.Add("/assets/checkout-o2N34dPp.css")
+
+	tags, err := a.PrefetchEntryTags("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`<link href="/assets/checkout-o2N34dPp.css" rel="prefetch"/>`,
+		`<link href="/assets/checkout-Dq1cWbUa.js" rel="prefetch"/>`,
+	}
+	if len(tags) != len(expected) || string(tags[0]) != expected[0] || string(tags[1]) != expected[1] {
+		t.Errorf("Unexpected prefetch tags. Expected: %v\nGot:%v\n", expected, tags)
+	}
+}
@@ -0,0 +1,61 @@
+package asset
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// ScanHTTPFS walks root directory of http.FileSystem (e.g. from vfs library or [http.FS]) and maps
+// all files to AssetMapper. Asset paths are relative to root, same as in [AssetMapper.ScanRoots].
+// Scan options work the same way as in [AssetMapper.ScanFS].
+//
+// Example:
+//
+//	err := assetMapper.ScanHTTPFS(http.Dir("public"), "/")
+func (a *AssetMapper) ScanHTTPFS(hfs http.FileSystem, root string) error {
+	root = strings.TrimPrefix(path.Clean("/"+root), "/")
+	if root == "" {
+		root = "."
+	}
+
+	return a.scanFS(httpFS{hfs}, root, "", time.Time{}, func(name string) string {
+		return relName(root, name)
+	}, func(asset *Asset) error {
+		a.AddAsset(asset, false)
+		return nil
+	})
+}
+
+// httpFS adapts http.FileSystem to fs.FS, so it can be scanned like any other file system.
+type httpFS struct {
+	hfs http.FileSystem
+}
+
+func (h httpFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f, err := h.hfs.Open(path.Join("/", name))
+	if err != nil {
+		return nil, err
+	}
+	return httpFile{f}, nil
+}
+
+// httpFile adds ReadDir to http.File, which is needed to walk directories.
+type httpFile struct {
+	http.File
+}
+
+func (f httpFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	infos, err := f.Readdir(n)
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, err
+}
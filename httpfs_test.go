@@ -0,0 +1,45 @@
+package asset
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanHTTPFS(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "static", "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"static/app.js":      "console.log(1)",
+		"static/css/app.css": "body { color: red; }",
+		"index.html":         "<html></html>",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var hfs http.FileSystem = http.Dir(dir)
+
+	a := NewAssetMapper()
+	if err := a.ScanHTTPFS(hfs, "static"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"app.js":      "/app.js?v=" + sha256Prefix("console.log(1)", 10),
+		"css/app.css": "/css/app.css?v=" + sha256Prefix("body { color: red; }", 10),
+	}
+	for path, expected := range cases {
+		if result := a.Get(path); expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, expected, result)
+		}
+	}
+	if _, ok := a.Assets["index.html"]; ok {
+		t.Errorf("Files outside of root should not be mapped\n")
+	}
+}
@@ -0,0 +1,36 @@
+package asset
+
+import (
+	"encoding/json"
+	"html/template"
+)
+
+// ImportMapTag returns <script type="importmap"> mapping bare module specifiers to asset urls.
+// Every target is resolved with [AssetMapper.Get], so fingerprinted or versioned url is used.
+// Nothing is compiled, modules must be already built.
+//
+// Templates can't create maps, so mappings are usually passed from template data:
+//
+//	{{ importMapTag .ImportMap }}
+//
+// Result for map[string]string{"app": "assets/js/app.js"}:
+//
+//	<script type="importmap">{"imports":{"app":"/assets/js/app.js?v=0a1b2c3d4e"}}</script>
+func (a *AssetMapper) ImportMapTag(mappings map[string]string) (template.HTML, error) {
+	imports := map[string]string{}
+	for specifier, path := range mappings {
+		url, err := a.resolve(path)
+		if err != nil {
+			return "", err
+		}
+		imports[specifier] = url
+	}
+
+	// json escapes <, > and &, so content can't close the script tag
+	content, err := json.Marshal(map[string]map[string]string{"imports": imports})
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(`<script type="importmap">` + string(content) + `</script>`), nil
+}
@@ -0,0 +1,42 @@
+package asset
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestImportMapTag(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["assets/js/app.js"] = &Asset{
+		Path:       "assets/js/app.js",
+		Hash:       "123",
+		PublicPath: "/",
+	}
+
+	tag, err := a.ImportMapTag(map[string]string{
+		"app":      "assets/js/app.js",
+		"</script": "external.js",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := string(tag)
+	content, ok := strings.CutPrefix(s, `<script type="importmap">`)
+	if !ok || !strings.HasSuffix(content, "</script>") || strings.Count(s, "</script>") != 1 {
+		t.Fatalf("Import map should be single script tag. Got: %s\n", s)
+	}
+
+	var importMap struct {
+		Imports map[string]string `json:"imports"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(content, "</script>")), &importMap); err != nil {
+		t.Fatalf("Import map should be valid json: %v", err)
+	}
+
+	expected := "/assets/js/app.js?v=123"
+	if importMap.Imports["app"] != expected {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, importMap.Imports["app"])
+	}
+}
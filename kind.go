@@ -0,0 +1,92 @@
+package asset
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Kind is asset type recognized by file extension.
+type Kind int
+
+const (
+	KindOther Kind = iota
+	KindCSS
+	KindJS
+	KindImage
+	KindFont
+)
+
+// String returns lowercase kind name, e.g. "font".
+func (k Kind) String() string {
+	switch k {
+	case KindCSS:
+		return "css"
+	case KindJS:
+		return "js"
+	case KindImage:
+		return "image"
+	case KindFont:
+		return "font"
+	}
+	return "other"
+}
+
+// parseKind returns kind for name returned by [Kind.String].
+func parseKind(name string) (Kind, error) {
+	for _, k := range []Kind{KindOther, KindCSS, KindJS, KindImage, KindFont} {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return KindOther, fmt.Errorf("unknown asset kind %q", name)
+}
+
+// kindOf returns kind of file path, version query is ignored.
+func kindOf(path string) Kind {
+	path = fileKey(path)
+	switch {
+	case isCSS(path):
+		return KindCSS
+	case isJS(path):
+		return KindJS
+	case isImage(path):
+		return KindImage
+	case isFont(path):
+		return KindFont
+	}
+	return KindOther
+}
+
+// AssetsByKind returns mapped assets of given kind sorted by path. Files without extension,
+// like "LICENSE", are [KindOther].
+func (a *AssetMapper) AssetsByKind(kind Kind) []*Asset {
+	result := []*Asset{}
+	for _, asset := range a.uniqueAssets() {
+		// assets added manually may have only path set
+		if kindOf(cmp.Or(asset.File, asset.Path)) == kind {
+			result = append(result, asset)
+		}
+	}
+	return result
+}
+
+// AssetsByType returns public urls of mapped assets of kind named "css", "js", "image", "font"
+// or "other", sorted by asset path. Unknown name returns error.
+//
+// Example usage in template:
+//
+//	{{ range (assetsByType "font") }}
+//		{{ preloadTag . }}
+//	{{ end }}
+func (a *AssetMapper) AssetsByType(name string) ([]string, error) {
+	kind, err := parseKind(name)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := []string{}
+	for _, asset := range a.AssetsByKind(kind) {
+		urls = append(urls, a.publicURL(asset.String()))
+	}
+	return urls, nil
+}
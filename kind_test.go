@@ -0,0 +1,50 @@
+package asset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtensionlessFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"LICENSE", "api/users", "app.js"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.InferExtensions = true
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	a.Assets["robots"] = &Asset{Path: "robots", Hash: "123", PublicPath: "/"}
+
+	for _, name := range []string{"LICENSE", "api/users"} {
+		expected := "/" + name + "?v=" + sha256Prefix(name, 10)
+		if result := a.Get(name); expected != result {
+			t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+		}
+	}
+
+	other := []string{}
+	for _, asset := range a.AssetsByKind(KindOther) {
+		other = append(other, asset.Path)
+	}
+	expected := "[LICENSE api/users robots]"
+	if result := fmt.Sprint(other); expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	entry := a.CreateEntry("docs")
+	entry.Add(a.Get("LICENSE"))
+	if len(entry.Other) != 1 || len(entry.CSS)+len(entry.JS) != 0 {
+		t.Errorf("Extensionless file should be added to entry as other file. Got: %v\n", entry.Other)
+	}
+}
@@ -1,8 +1,16 @@
 package asset
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
 type ManifestType int
@@ -10,15 +18,223 @@ type ManifestType int
 const (
 	ViteManifestType ManifestType = iota
 	WebpackManifestType
+	// PassthroughManifestType is JSON object of entry name to list of its files, which are
+	// served under the same path, e.g. {"app": ["js/app.js", "css/app.css"]}. It allows using
+	// entry helpers for builds without fingerprinting.
+	PassthroughManifestType
+	// SymfonyManifestType is manifest.json compiled by Symfony AssetMapper, JSON object of logical
+	// path to versioned public path, e.g. {"app.js": "/assets/app-0a1b2c3d.js"}.
+	SymfonyManifestType
 )
 
+// ErrUnknownManifestType is returned for manifest type other than declared ManifestType constants.
+var ErrUnknownManifestType = errors.New("undefined manifest type")
+
+// ErrManifestTooLarge is returned for manifest bigger than [AssetMapper.MaxManifestSize].
+var ErrManifestTooLarge = errors.New("manifest too large")
+
+// DefaultMaxManifestSize is [AssetMapper.MaxManifestSize] set by [NewAssetMapper]. Mappers
+// created before the limit was introduced loaded manifests of any size, set MaxManifestSize
+// to zero to keep that behavior.
+const DefaultMaxManifestSize = 32 << 20
+
 // ManifestConfig provides information about manifest filepath and how to parse it correctly.
-// Currently supports Vite or Webpack types.
+// Currently supports Vite, Webpack, Symfony AssetMapper or passthrough types.
 type ManifestConfig struct {
 	// manifest filepath
 	Path string
 	// manifest generator type
 	Type ManifestType
+	// Entries limits loaded records to listed entry names and their imports.
+	// Empty means load everything. Supported by Vite manifest.
+	Entries []string
+	// Namespace is prefixed to entry names as "namespace:name", so entries with the same
+	// name from different manifests don't overwrite each other.
+	Namespace string
+	// VerifyFiles checks that every output file listed in manifest exists in Dir.
+	VerifyFiles bool
+	// Dir is directory with manifest output files, used by VerifyFiles and
+	// [AssetMapper.SkipManifestOutputs]. Defaults to manifest directory, or its parent for
+	// manifest stored in ".vite" directory.
+	Dir string
+}
+
+// Validate checks that config has manifest path and known type, so misconfiguration can be
+// reported before manifest is loaded.
+func (c ManifestConfig) Validate() error {
+	if c.Path == "" {
+		return errors.New("manifest path is empty")
+	}
+
+	switch c.Type {
+	case ViteManifestType, WebpackManifestType, PassthroughManifestType, SymfonyManifestType:
+		return nil
+	}
+	return fmt.Errorf("%w: %d", ErrUnknownManifestType, c.Type)
+}
+
+// verifyFiles returns error listing all files missing in config Dir, if VerifyFiles is set.
+// Absolute urls, e.g. files served from CDN, are not verified.
+func (c ManifestConfig) verifyFiles(files []string) error {
+	if !c.VerifyFiles {
+		return nil
+	}
+
+	dir := c.dir()
+	slices.Sort(files)
+	errs := []error{}
+	for _, file := range slices.Compact(files) {
+		if isAbsoluteURL(file) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file))); err != nil {
+			errs = append(errs, fmt.Errorf("manifest output file %s: %w", file, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// dir returns directory with manifest output files, look [ManifestConfig] Dir.
+func (c ManifestConfig) dir() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+
+	dir := filepath.Dir(c.Path)
+	if filepath.Base(dir) == ".vite" {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// entryName returns entry name with config namespace prefix.
+func (c ManifestConfig) entryName(name string) string {
+	if c.Namespace == "" {
+		return name
+	}
+	return c.Namespace + ":" + name
+}
+
+// manifestTypeNames are manifest type names used in manifest index, look [AssetMapper.UseManifestIndex]
+var manifestTypeNames = map[string]ManifestType{
+	"vite":        ViteManifestType,
+	"webpack":     WebpackManifestType,
+	"passthrough": PassthroughManifestType,
+	"symfony":     SymfonyManifestType,
+}
+
+type manifestIndex struct {
+	Manifests []struct {
+		Path      string   `json:"path"`
+		Type      string   `json:"type"`
+		Namespace string   `json:"namespace"`
+		Entries   []string `json:"entries"`
+	} `json:"manifests"`
+}
+
+// UseManifestIndex loads manifests listed in index file, e.g. one manifest per micro-frontend.
+// Manifest paths are relative to index file directory. All manifests are loaded all-or-nothing
+// with [AssetMapper.UseManifests].
+//
+// Example index:
+//
+//	{
+//		"manifests": [
+//			{"path": "shop/.vite/manifest.json", "type": "vite", "namespace": "shop"},
+//			{"path": "blog/manifest.json", "type": "webpack", "namespace": "blog"}
+//		]
+//	}
+func (a *AssetMapper) UseManifestIndex(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r, tooLarge := a.limitManifest(file)
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if err := tooLarge(); err != nil {
+		return fmt.Errorf("manifest index %s: %w", path, err)
+	}
+
+	var index manifestIndex
+	if err := json.Unmarshal(content, &index); err != nil {
+		return fmt.Errorf("manifest index %s: %w", path, err)
+	}
+
+	configs := []ManifestConfig{}
+	for _, m := range index.Manifests {
+		typ, ok := manifestTypeNames[m.Type]
+		if !ok {
+			return fmt.Errorf("manifest index %s: unknown manifest type %q", path, m.Type)
+		}
+
+		manifestPath := m.Path
+		if !filepath.IsAbs(manifestPath) {
+			manifestPath = filepath.Join(filepath.Dir(path), filepath.FromSlash(manifestPath))
+		}
+
+		configs = append(configs, ManifestConfig{
+			Path:      manifestPath,
+			Type:      typ,
+			Namespace: m.Namespace,
+			Entries:   m.Entries,
+		})
+	}
+
+	return a.UseManifests(configs...)
+}
+
+// DiffManifests compares two manifests of the same type and returns sorted asset paths which were
+// added, removed or point to different output file in new manifest. Useful for targeted cache purging
+// after deploy. Manifests are loaded into separate mappers, so no existing mapper is changed.
+func DiffManifests(oldPath, newPath string, typ ManifestType) (added, removed, changed []string, err error) {
+	load := func(path string) (map[string]string, error) {
+		a := NewAssetMapper()
+		if err := a.UseManifest(ManifestConfig{Path: path, Type: typ}); err != nil {
+			return nil, err
+		}
+
+		files := map[string]string{}
+		for _, asset := range a.uniqueAssets() {
+			files[asset.Path] = asset.String()
+		}
+		return files, nil
+	}
+
+	oldFiles, err := load(oldPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	newFiles, err := load(newPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for path, file := range newFiles {
+		oldFile, ok := oldFiles[path]
+		switch {
+		case !ok:
+			added = append(added, path)
+		case oldFile != file:
+			changed = append(changed, path)
+		}
+	}
+	for path := range oldFiles {
+		if _, ok := newFiles[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+
+	slices.Sort(added)
+	slices.Sort(removed)
+	slices.Sort(changed)
+
+	return added, removed, changed, nil
 }
 
 type viteManifestRecord struct {
@@ -29,75 +245,287 @@ type viteManifestRecord struct {
 	CSS            []string `json:"css"`
 	Imports        []string `json:"imports"`
 	IsDynamicEntry bool     `json:"isDynamicEntry"`
+	DynamicImports []string `json:"dynamicImports"`
+	Assets         []string `json:"assets"`
 }
 
-func parseViteManifest(path string, a *AssetMapper) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	decoder := json.NewDecoder(file)
+func parseViteManifest(config ManifestConfig, r io.Reader, a *AssetMapper) error {
+	decoder := json.NewDecoder(r)
+	files := []string{}
 
 	for decoder.More() {
 		var data map[string]viteManifestRecord
 
-		err = decoder.Decode(&data)
+		err := decoder.Decode(&data)
 		if err != nil {
 			return err
 		}
 
+		allowed := viteAllowedRecords(data, config.Entries)
+
+		// several records can share the same name, such names are ambiguous
+		names := map[string]int{}
+		entryNames := map[string]int{}
+		for k, v := range data {
+			if allowed != nil && !allowed[k] {
+				continue
+			}
+			names[v.Name]++
+			if v.IsEntry {
+				entryNames[v.Name]++
+			}
+		}
+
 		for k, v := range data {
+			if allowed != nil && !allowed[k] {
+				continue
+			}
+
 			asset := &Asset{
-				Path:       k,
-				PublicPath: a.PublicPath + v.File,
-				Hash:       "",
+				Path:          k,
+				PublicPath:    a.PublicPath,
+				File:          v.File,
+				Hash:          "",
+				Fingerprinted: true,
 			}
 
-			a.Assets[k] = asset
+			a.AddAsset(asset, true)
+			files = append(files, v.File)
+			files = append(files, v.CSS...)
+
+			// allow lookups by src and chunk name, when they differ from manifest key
+			if v.Src != "" && v.Src != k {
+				a.Assets[v.Src] = asset
+			}
+			if _, ok := a.Assets[config.entryName(v.Name)]; v.Name != "" && names[v.Name] == 1 && !ok {
+				a.Assets[config.entryName(v.Name)] = asset
+			}
+
+			// css and static files imported by chunk are available by output path
+			for _, file := range slices.Concat(v.CSS, v.Assets) {
+				a.AddAsset(&Asset{
+					Path:          file,
+					PublicPath:    a.PublicPath,
+					File:          file,
+					Fingerprinted: true,
+				}, false)
+			}
+			files = append(files, v.Assets...)
+
 			if v.IsEntry {
-				entry := a.CreateEntry(v.Name)
-				entry.Add(asset.PublicPath)
+				// entries sharing a name are registered by manifest key, so they are not merged
+				name := v.Name
+				if entryNames[v.Name] > 1 {
+					name = k
+				}
 
-				for _, css := range v.CSS {
-					entry.Add(a.PublicPath + css)
+				entry := a.CreateEntry(config.entryName(name))
+				entry.Dir = path.Dir(cmp.Or(v.Src, k))
+				entry.Add(asset.String())
+
+				for _, css := range viteEntryCSS(data, k) {
+					entry.Add(joinURL(a.PublicPath, css))
+				}
+				for _, file := range v.Assets {
+					entry.Assets = append(entry.Assets, joinURL(a.PublicPath, file))
+				}
+				for _, imported := range v.DynamicImports {
+					if record, ok := data[imported]; ok {
+						entry.Prefetch = append(entry.Prefetch, joinURL(a.PublicPath, record.File))
+					}
 				}
 			}
 
 		}
 	}
 
-	return nil
+	return a.manifestFiles(config, files)
 }
 
-func parseWebpackManifest(path string, a *AssetMapper) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
+// viteEntryCSS returns css files of record and all records imported by it, directly or transitively.
+// CSS of imported chunks comes before importer CSS, the same order as Vite injects it.
+func viteEntryCSS(data map[string]viteManifestRecord, key string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	visited := map[string]bool{}
+
+	var visit func(k string)
+	visit = func(k string) {
+		if visited[k] {
+			return
+		}
+		visited[k] = true
+
+		record := data[k]
+		for _, imported := range record.Imports {
+			visit(imported)
+		}
+		for _, css := range record.CSS {
+			if !seen[css] {
+				seen[css] = true
+				result = append(result, css)
+			}
+		}
 	}
-	defer file.Close()
+	visit(key)
+
+	return result
+}
+
+// viteAllowedRecords returns set of manifest keys belonging to listed entries, including
+// records imported by them. Returns nil when entries is empty, meaning no filtering.
+func viteAllowedRecords(data map[string]viteManifestRecord, entries []string) map[string]bool {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	queue := []string{}
+	for k, v := range data {
+		if v.IsEntry && slices.Contains(entries, v.Name) {
+			queue = append(queue, k)
+		}
+	}
+
+	allowed := map[string]bool{}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+
+		if allowed[k] {
+			continue
+		}
+		allowed[k] = true
+		queue = append(queue, data[k].Imports...)
+	}
+
+	return allowed
+}
+
+// webpackEntrypoint is entry record of "entrypoints" manifest key, as generated by
+// webpack-assets-manifest (files nested in "assets") or Symfony Encore (files on top level).
+type webpackEntrypoint struct {
+	Assets struct {
+		JS  []string `json:"js"`
+		CSS []string `json:"css"`
+	} `json:"assets"`
+	JS  []string `json:"js"`
+	CSS []string `json:"css"`
+}
 
-	decoder := json.NewDecoder(file)
+func parseWebpackManifest(config ManifestConfig, r io.Reader, a *AssetMapper) error {
+	decoder := json.NewDecoder(r)
+	files := []string{}
 
 	for decoder.More() {
-		var data map[string]string
+		var data map[string]json.RawMessage
 
-		err = decoder.Decode(&data)
+		err := decoder.Decode(&data)
 		if err != nil {
 			return err
 		}
 
-		for k := range data {
+		for k, raw := range data {
+			if k == "entrypoints" {
+				var entrypoints map[string]webpackEntrypoint
+				if err := json.Unmarshal(raw, &entrypoints); err != nil {
+					return fmt.Errorf("manifest entrypoints: %w", err)
+				}
+
+				for name, e := range entrypoints {
+					entry := a.CreateEntry(config.entryName(name))
+					for _, file := range slices.Concat(e.Assets.CSS, e.CSS, webpackEntryJS(name, slices.Concat(e.Assets.JS, e.JS))) {
+						entry.Add(joinURL(a.PublicPath, file))
+					}
+				}
+				continue
+			}
+
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("manifest key %s: %w", k, err)
+			}
+
+			// Laravel Mix style keys start with slash, values may contain version query
 			asset := &Asset{
-				Path:       k,
-				PublicPath: a.PublicPath,
-				Hash:       "",
+				Path:          strings.TrimLeft(k, "/"),
+				PublicPath:    a.PublicPath,
+				File:          v,
+				Hash:          "",
+				Fingerprinted: true,
 			}
 
-			a.Assets[k] = asset
+			a.AddAsset(asset, true)
+			files = append(files, fileKey(v))
 		}
 
 	}
-	return nil
+	return a.manifestFiles(config, files)
+}
+
+// webpackEntryJS moves main script of entry after its dependency chunks (runtime, vendors), keeping
+// order of the rest. Main script is the one named after entry, e.g. "app.js" or "app.0a1b2c3d.js".
+func webpackEntryJS(name string, files []string) []string {
+	result := make([]string, 0, len(files))
+	main := []string{}
+	for _, file := range files {
+		base := path.Base(fileKey(file))
+		if strings.HasPrefix(base, name+".") || strings.HasPrefix(base, name+"-") {
+			main = append(main, file)
+			continue
+		}
+		result = append(result, file)
+	}
+	return append(result, main...)
+}
+
+func parsePassthroughManifest(config ManifestConfig, r io.Reader, a *AssetMapper) error {
+	var data map[string][]string
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+
+	files := []string{}
+	for name, entryFiles := range data {
+		entry := a.CreateEntry(config.entryName(name))
+		for _, file := range entryFiles {
+			file = strings.TrimLeft(file, "/")
+			a.AddAsset(&Asset{
+				Path:       file,
+				PublicPath: a.PublicPath,
+				File:       file,
+			}, false)
+
+			entry.Add(joinURL(a.PublicPath, file))
+			files = append(files, file)
+		}
+	}
+
+	return a.manifestFiles(config, files)
+}
+
+func parseSymfonyManifest(config ManifestConfig, r io.Reader, a *AssetMapper) error {
+	var data map[string]string
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+
+	// values are public paths including assets prefix, which may be part of PublicPath too
+	prefix := a.PublicPathPrefix()
+	files := []string{}
+	for k, v := range data {
+		file := strings.TrimPrefix(v, prefix)
+		if file == v {
+			file = strings.TrimLeft(v, "/")
+		}
+
+		a.AddAsset(&Asset{
+			Path:          k,
+			PublicPath:    a.PublicPath,
+			File:          file,
+			Fingerprinted: true,
+		}, true)
+		files = append(files, file)
+	}
+
+	return a.manifestFiles(config, files)
 }
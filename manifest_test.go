@@ -0,0 +1,810 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestViteManifestLookupBySrcKeyAndName(t *testing.T) {
+	path := writeManifest(t, `{
+  "_app.js": {
+    "file": "assets/app-CKgRTByK.js",
+    "name": "app",
+    "src": "src/app.js",
+    "isEntry": true
+  }
+}`)
+
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/static/assets/app-CKgRTByK.js"
+	for _, search := range []string{"_app.js", "src/app.js", "app"} {
+		result := a.Get(search)
+		if expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", search, expected, result)
+		}
+	}
+}
+
+const multiEntryViteManifest = `{
+  "src/app.js": {
+    "file": "assets/app-CKgRTByK.js",
+    "name": "app",
+    "src": "src/app.js",
+    "isEntry": true,
+    "imports": ["_shared-B7PI925R.js"]
+  },
+  "src/admin.js": {
+    "file": "assets/admin-Dq1cWbUa.js",
+    "name": "admin",
+    "src": "src/admin.js",
+    "isEntry": true,
+    "css": ["assets/admin-o2N34dPp.css"]
+  },
+  "_shared-B7PI925R.js": {
+    "file": "assets/shared-B7PI925R.js",
+    "name": "shared"
+  }
+}`
+
+func TestViteManifestEntriesAllowlist(t *testing.T) {
+	path := writeManifest(t, multiEntryViteManifest)
+
+	a := NewAssetMapper()
+	err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType, Entries: []string{"app"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := a.Entries["app"]; !ok {
+		t.Errorf("app entry should be loaded\n")
+	}
+	if _, ok := a.Entries["admin"]; ok {
+		t.Errorf("admin entry should not be loaded\n")
+	}
+	if _, ok := a.Assets["_shared-B7PI925R.js"]; !ok {
+		t.Errorf("app entry dependency should be loaded\n")
+	}
+	if _, ok := a.Assets["src/admin.js"]; ok {
+		t.Errorf("admin entry asset should not be loaded\n")
+	}
+	if _, ok := a.Assets["assets/admin-o2N34dPp.css"]; ok {
+		t.Errorf("admin entry css should not be loaded\n")
+	}
+}
+
+func TestManifestNamespace(t *testing.T) {
+	shop := writeManifest(t, `{
+  "src/app.js": {"file": "assets/shop-CKgRTByK.js", "name": "app", "src": "src/app.js", "isEntry": true}
+}`)
+	admin := writeManifest(t, `{
+  "src/app.js": {"file": "assets/admin-Dq1cWbUa.js", "name": "app", "src": "src/app.js", "isEntry": true}
+}`)
+
+	a := NewAssetMapper()
+	if err := a.UseManifest(ManifestConfig{Path: shop, Type: ViteManifestType, Namespace: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UseManifest(ManifestConfig{Path: admin, Type: ViteManifestType, Namespace: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"shop:app":  "/assets/shop-CKgRTByK.js",
+		"admin:app": "/assets/admin-Dq1cWbUa.js",
+	}
+	for name, expected := range cases {
+		js := a.JSEntry(name)
+		if len(js) != 1 || js[0] != expected {
+			t.Errorf("Entry %s should contain %s. Got: %v\n", name, expected, js)
+		}
+	}
+
+	if _, ok := a.Entries["app"]; ok {
+		t.Errorf("Entry without namespace should not exist\n")
+	}
+}
+
+func TestManifestVerifyFiles(t *testing.T) {
+	path := writeManifest(t, `{
+  "src/app.js": {
+    "file": "assets/app-CKgRTByK.js",
+    "name": "app",
+    "src": "src/app.js",
+    "isEntry": true,
+    "css": ["assets/app-o2N34dPp.css"]
+  }
+}`)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assets", "app-CKgRTByK.js"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType}); err != nil {
+		t.Errorf("Files should not be verified by default. Got: %v\n", err)
+	}
+
+	err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType, VerifyFiles: true})
+	if err == nil {
+		t.Fatal("Missing output file error expected")
+	}
+	if !strings.Contains(err.Error(), "assets/app-o2N34dPp.css") || strings.Contains(err.Error(), "app-CKgRTByK.js") {
+		t.Errorf("Error should list only missing css file. Got: %v\n", err)
+	}
+}
+
+func TestWebpackManifestVerifyQueryVersionedFiles(t *testing.T) {
+	path := writeManifest(t, `{
+  "/js/app.js": "/js/app.js?id=6c4f3b4e1b9b8f1d",
+  "/css/app.css": "/css/app.css?id=0d1c2b3a"
+}`)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(filepath.Join(dir, "js"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "js", "app.js"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	err := a.UseManifest(ManifestConfig{Path: path, Type: WebpackManifestType, VerifyFiles: true})
+	if err == nil {
+		t.Fatal("Missing output file error expected")
+	}
+	if !strings.Contains(err.Error(), "manifest output file css/app.css:") || strings.Contains(err.Error(), "js/app.js") {
+		t.Errorf("Error should list only missing css file without query. Got: %v\n", err)
+	}
+}
+
+func TestWithEntryResolution(t *testing.T) {
+	path := writeManifest(t, `{
+  "src/admin/main.js": {"file": "assets/admin-Dq1cWbUa.js", "name": "admin", "src": "src/admin/main.js", "isEntry": true},
+  "src/shop/main.js": {"file": "assets/shop-CKgRTByK.js", "name": "shop", "src": "src/shop/main.js", "isEntry": true},
+  "src/admin/logo.png": {"file": "assets/logo-B7PI925R.png", "src": "src/admin/logo.png"},
+  "src/shop/logo.png": {"file": "assets/logo-DXdl7YkJ.png", "src": "src/shop/logo.png"},
+  "logo.png": {"file": "assets/logo-o2N34dPp.png", "src": "logo.png"}
+}`)
+
+	a := NewAssetMapper()
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"admin": "/assets/logo-B7PI925R.png",
+		"shop":  "/assets/logo-DXdl7YkJ.png",
+		"":      "/assets/logo-o2N34dPp.png",
+	}
+	for entry, expected := range cases {
+		result := a.WithEntry(entry).Get("logo.png")
+		if expected != result {
+			t.Errorf("String should be equal for entry %q. Expected: %s\nGot:%s\n", entry, expected, result)
+		}
+	}
+
+	expected := "/assets/logo-o2N34dPp.png"
+	result := a.Get("logo.png")
+	if expected != result {
+		t.Errorf("Original mapper should not be scoped. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestWebpackManifestQueryVersionedValues(t *testing.T) {
+	path := writeManifest(t, `{
+  "/js/app.js": "/js/app.js?id=6c4f3b4e1b9b8f1d",
+  "/css/app.css": "/css/app.css?id=0d1c2b3a"
+}`)
+
+	a := NewAssetMapper()
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: WebpackManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"/js/app.js":  "/js/app.js?id=6c4f3b4e1b9b8f1d",
+		"js/app.js":   "/js/app.js?id=6c4f3b4e1b9b8f1d",
+		"css/app.css": "/css/app.css?id=0d1c2b3a",
+	}
+	for search, expected := range cases {
+		result := a.Get(search)
+		if expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", search, expected, result)
+		}
+	}
+}
+
+func TestViteManifestEntryNameCollision(t *testing.T) {
+	path := writeManifest(t, `{
+  "src/admin/index.js": {"file": "assets/index-Dq1cWbUa.js", "name": "index", "src": "src/admin/index.js", "isEntry": true},
+  "src/shop/index.js": {"file": "assets/index-CKgRTByK.js", "name": "index", "src": "src/shop/index.js", "isEntry": true},
+  "src/app.js": {"file": "assets/app-B7PI925R.js", "name": "app", "src": "src/app.js", "isEntry": true}
+}`)
+
+	a := NewAssetMapper()
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := a.Entries["index"]; ok {
+		t.Errorf("Colliding entries should not be merged under shared name\n")
+	}
+
+	cases := map[string]string{
+		"src/admin/index.js": "/assets/index-Dq1cWbUa.js",
+		"src/shop/index.js":  "/assets/index-CKgRTByK.js",
+		"app":                "/assets/app-B7PI925R.js",
+	}
+	for name, expected := range cases {
+		js := a.JSEntry(name)
+		if len(js) != 1 || js[0] != expected {
+			t.Errorf("Entry %s should contain only %s. Got: %v\n", name, expected, js)
+		}
+	}
+
+	if result := a.Get("index"); result != "index" {
+		t.Errorf("Ambiguous name should not resolve to asset. Got: %s\n", result)
+	}
+}
+
+func TestDiffManifests(t *testing.T) {
+	oldPath := writeManifest(t, `{
+  "src/app.js": {"file": "assets/app-CKgRTByK.js", "name": "app", "isEntry": true},
+  "src/admin.js": {"file": "assets/admin-Dq1cWbUa.js", "name": "admin", "isEntry": true},
+  "src/legacy.js": {"file": "assets/legacy-B7PI925R.js", "name": "legacy", "isEntry": true}
+}`)
+	newPath := writeManifest(t, `{
+  "src/app.js": {"file": "assets/app-BfX2d9kQ.js", "name": "app", "isEntry": true},
+  "src/admin.js": {"file": "assets/admin-Dq1cWbUa.js", "name": "admin", "isEntry": true},
+  "src/checkout.js": {"file": "assets/checkout-o2N34dPp.js", "name": "checkout", "isEntry": true}
+}`)
+
+	added, removed, changed, err := DiffManifests(oldPath, newPath, ViteManifestType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		result   []string
+		expected []string
+	}{
+		{"added", added, []string{"src/checkout.js"}},
+		{"removed", removed, []string{"src/legacy.js"}},
+		{"changed", changed, []string{"src/app.js"}},
+	} {
+		if strings.Join(c.result, ",") != strings.Join(c.expected, ",") {
+			t.Errorf("Unexpected %s assets. Expected: %v\nGot:%v\n", c.name, c.expected, c.result)
+		}
+	}
+}
+
+func TestViteEntryCSSFromImportedChunks(t *testing.T) {
+	path := writeManifest(t, `{
+  "src/app.js": {
+    "file": "assets/app-CKgRTByK.js",
+    "name": "app",
+    "src": "src/app.js",
+    "isEntry": true,
+    "imports": ["_routes-B7PI925R.js", "_shared-Dq1cWbUa.js"],
+    "css": ["assets/app-o2N34dPp.css"]
+  },
+  "_routes-B7PI925R.js": {
+    "file": "assets/routes-B7PI925R.js",
+    "imports": ["_shared-Dq1cWbUa.js"],
+    "css": ["assets/routes-BfX2d9kQ.css"]
+  },
+  "_shared-Dq1cWbUa.js": {
+    "file": "assets/shared-Dq1cWbUa.js",
+    "css": ["assets/shared-C2p4xNq1.css"]
+  }
+}`)
+
+	a := NewAssetMapper()
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"/assets/shared-C2p4xNq1.css",
+		"/assets/routes-BfX2d9kQ.css",
+		"/assets/app-o2N34dPp.css",
+	}
+	result := a.CSSEntry("app")
+	if strings.Join(expected, ",") != strings.Join(result, ",") {
+		t.Errorf("Unexpected entry css. Expected: %v\nGot:%v\n", expected, result)
+	}
+}
+
+func TestUseManifestBytes(t *testing.T) {
+	a := NewAssetMapper()
+	err := a.UseManifestBytes([]byte(`{
+  "src/app.js": {"file": "assets/app-CKgRTByK.js", "name": "app", "src": "src/app.js", "isEntry": true}
+}`), ViteManifestType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/assets/app-CKgRTByK.js"
+	result := a.Get("src/app.js")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+	if len(a.JSEntry("app")) != 1 {
+		t.Errorf("Entry should be loaded from bytes. Got: %v\n", a.JSEntry("app"))
+	}
+}
+
+func TestEntryNames(t *testing.T) {
+	a := NewAssetMapper()
+	if err := a.UseManifestBytes([]byte(multiEntryViteManifest), ViteManifestType); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "admin,app"
+	result := strings.Join(a.EntryNames(), ",")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestSkipManifestOutputs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".vite"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		".vite/manifest.json":    `{"src/app.js": {"file": "assets/app-CKgRTByK.js", "name": "app", "isEntry": true}}`,
+		"assets/app-CKgRTByK.js": "console.log(1)",
+		"favicon.ico":            "ico",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	a.SkipManifestOutputs = true
+	if err := a.UseManifest(ManifestConfig{Path: filepath.Join(dir, ".vite", "manifest.json"), Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	scanned := []string{}
+	a.PostScan = func(asset *Asset) error {
+		scanned = append(scanned, asset.Path)
+		return nil
+	}
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(scanned, ",") != "favicon.ico" {
+		t.Errorf("Only files not covered by manifest should be scanned. Got: %v\n", scanned)
+	}
+	if _, ok := a.Assets[".vite/manifest.json"]; ok {
+		t.Errorf("Manifest file should not be mapped\n")
+	}
+
+	expected := "/assets/app-CKgRTByK.js"
+	result := a.Get("assets/app-CKgRTByK.js")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+}
+
+func TestSkipManifestOutputsDefaultOptions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"public/.vite", "public/assets"} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"public/.vite/manifest.json":     `{"src/app.js": {"file": "assets/app-CKgRTByK.js", "name": "app", "isEntry": true, "css": ["assets/app-o2N34dPp.css"]}}`,
+		"public/assets/app-CKgRTByK.js":  "console.log(1)",
+		"public/assets/app-o2N34dPp.css": "body {}",
+		"public/favicon.ico":             "ico",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.SkipManifestOutputs = true
+	if err := a.UseManifest(ManifestConfig{Path: "public/.vite/manifest.json", Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ScanDir("public"); err != nil {
+		t.Fatal(err)
+	}
+
+	for path := range a.Assets {
+		if strings.HasPrefix(path, "public/") && path != "public/favicon.ico" {
+			t.Errorf("Manifest output should not be scanned: %s\n", path)
+		}
+	}
+	if _, ok := a.Assets["public/favicon.ico"]; !ok {
+		t.Errorf("File not covered by manifest should be scanned\n")
+	}
+}
+
+func TestViteManifestAbsoluteFile(t *testing.T) {
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	err := a.UseManifestBytes([]byte(`{
+  "src/app.js": {"file": "/build/app-CKgRTByK.js", "name": "app", "isEntry": true, "css": ["/build/app-o2N34dPp.css"]},
+  "src/cdn.js": {"file": "https://cdn.example.com/cdn-B7PI925R.js", "name": "cdn"}
+}`), ViteManifestType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// root relative files already point to final location, so PublicPath isn't added
+	cases := map[string]string{
+		"src/app.js":             "/build/app-CKgRTByK.js",
+		"build/app-CKgRTByK.js":  "/build/app-CKgRTByK.js",
+		"build/app-o2N34dPp.css": "/build/app-o2N34dPp.css",
+		"src/cdn.js":             "https://cdn.example.com/cdn-B7PI925R.js",
+	}
+	for path, expected := range cases {
+		result := a.Get(path)
+		if expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, expected, result)
+		}
+	}
+
+	expected := "/build/app-o2N34dPp.css"
+	if css := a.CSSEntry("app"); len(css) != 1 || css[0] != expected {
+		t.Errorf("Unexpected entry css. Expected: %s\nGot:%v\n", expected, css)
+	}
+}
+
+func TestViteManifestVerifyAbsoluteFiles(t *testing.T) {
+	path := writeManifest(t, `{
+  "src/app.js": {"file": "assets/app-CKgRTByK.js", "name": "app", "isEntry": true, "css": ["https://cdn.example.com/app-o2N34dPp.css"]},
+  "src/cdn.js": {"file": "//cdn.example.com/cdn-B7PI925R.js", "name": "cdn"}
+}`)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assets", "app-CKgRTByK.js"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssetMapper()
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: ViteManifestType, VerifyFiles: true}); err != nil {
+		t.Errorf("Absolute urls should not be verified. Got: %v\n", err)
+	}
+}
+
+func TestViteManifestRecordAssets(t *testing.T) {
+	a := NewAssetMapper()
+	err := a.UseManifestBytes([]byte(`{
+  "src/app.js": {
+    "file": "assets/app-CKgRTByK.js",
+    "name": "app",
+    "isEntry": true,
+    "assets": ["assets/logo-BfX2d9kQ.png", "assets/inter-C2p4xNq1.woff2"]
+  }
+}`), ViteManifestType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/assets/logo-BfX2d9kQ.png"
+	result := a.Get("assets/logo-BfX2d9kQ.png")
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	preloads, _ := a.HeadLinks("app")
+	expectedLinks := []string{
+		"</assets/app-CKgRTByK.js>; rel=preload; as=script",
+		"</assets/logo-BfX2d9kQ.png>; rel=preload; as=image",
+		"</assets/inter-C2p4xNq1.woff2>; rel=preload; as=font; crossorigin",
+	}
+	if strings.Join(expectedLinks, ",") != strings.Join(preloads, ",") {
+		t.Errorf("Unexpected preloads. Expected: %v\nGot:%v\n", expectedLinks, preloads)
+	}
+}
+
+func TestUseManifestsAllOrNothing(t *testing.T) {
+	valid := writeManifest(t, multiEntryViteManifest)
+	invalid := writeManifest(t, `{"src/broken.js": {"file": `)
+
+	a := NewAssetMapper()
+	a.AddAsset(&Asset{Path: "favicon.ico", Hash: "123", PublicPath: a.PublicPath}, false)
+	a.CreateEntry("existing").Add("/existing.js")
+
+	err := a.UseManifests(
+		ManifestConfig{Path: valid, Type: ViteManifestType},
+		ManifestConfig{Path: invalid, Type: WebpackManifestType},
+	)
+	if err == nil {
+		t.Fatal("Invalid manifest should return error")
+	}
+
+	if len(a.Assets) != 1 || a.Get("favicon.ico") != "/favicon.ico?v=123" {
+		t.Errorf("Assets should stay unchanged. Got: %v\n", a.Assets)
+	}
+	if strings.Join(a.EntryNames(), ",") != "existing" || len(a.JSEntry("existing")) != 1 {
+		t.Errorf("Entries should stay unchanged. Got: %v\n", a.EntryNames())
+	}
+
+	if err := a.UseManifests(ManifestConfig{Path: valid, Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(a.EntryNames(), ",") != "admin,app,existing" {
+		t.Errorf("Valid manifests should be loaded. Got: %v\n", a.EntryNames())
+	}
+}
+
+func TestWebpackEntrypointsMainLast(t *testing.T) {
+	a := NewAssetMapper()
+	a.PublicPath = "/build/"
+	err := a.UseManifestBytes([]byte(`{
+  "app.js": "app.0a1b2c3d.js",
+  "entrypoints": {
+    "app": {
+      "assets": {
+        "js": ["app.0a1b2c3d.js", "runtime.4e5f6a7b.js", "vendors~app.8c9d0e1f.js"],
+        "css": ["app.2a3b4c5d.css"]
+      }
+    }
+  }
+}`), WebpackManifestType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"/build/runtime.4e5f6a7b.js", "/build/vendors~app.8c9d0e1f.js", "/build/app.0a1b2c3d.js"}
+	result := a.JSEntry("app")
+	if strings.Join(expected, ",") != strings.Join(result, ",") {
+		t.Errorf("Main script should be last. Expected: %v\nGot:%v\n", expected, result)
+	}
+
+	if css := a.CSSEntry("app"); len(css) != 1 || css[0] != "/build/app.2a3b4c5d.css" {
+		t.Errorf("Unexpected entry css. Got: %v\n", css)
+	}
+	if result := a.Get("app.js"); result != "/build/app.0a1b2c3d.js" {
+		t.Errorf("Asset should still be mapped. Got: %s\n", result)
+	}
+}
+
+func TestUseManifestIndex(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"shop/.vite", "blog"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"shop/.vite/manifest.json": `{"src/app.js": {"file": "assets/app-CKgRTByK.js", "name": "app", "isEntry": true}}`,
+		"blog/manifest.json":       `{"blog.js": "blog.0a1b2c3d.js"}`,
+		"index.json": `{"manifests": [
+  {"path": "shop/.vite/manifest.json", "type": "vite", "namespace": "shop"},
+  {"path": "blog/manifest.json", "type": "webpack", "namespace": "blog"}
+]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	if err := a.UseManifestIndex(filepath.Join(dir, "index.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	if js := a.JSEntry("shop:app"); len(js) != 1 || js[0] != "/assets/app-CKgRTByK.js" {
+		t.Errorf("Vite manifest should be loaded with namespace. Got: %v\n", js)
+	}
+	if result := a.Get("blog.js"); result != "/blog.0a1b2c3d.js" {
+		t.Errorf("Webpack manifest should be loaded. Got: %s\n", result)
+	}
+}
+
+func TestPassthroughManifest(t *testing.T) {
+	path := writeManifest(t, `{
+  "app": ["js/vendor.js", "js/app.js", "css/app.css"],
+  "admin": ["/js/admin.js"]
+}`)
+
+	a := NewAssetMapper()
+	a.PublicPath = "/static/"
+	if err := a.UseManifest(ManifestConfig{Path: path, Type: PassthroughManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"/static/js/vendor.js", "/static/js/app.js"}
+	if strings.Join(expected, ",") != strings.Join(a.JSEntry("app"), ",") {
+		t.Errorf("Unexpected entry js. Expected: %v\nGot:%v\n", expected, a.JSEntry("app"))
+	}
+	if css := a.CSSEntry("app"); len(css) != 1 || css[0] != "/static/css/app.css" {
+		t.Errorf("Unexpected entry css. Got: %v\n", css)
+	}
+	if result := a.Get("js/admin.js"); result != "/static/js/admin.js" {
+		t.Errorf("Files should be mapped to identical path. Got: %s\n", result)
+	}
+}
+
+func TestManifestConfigValidate(t *testing.T) {
+	valid := []ManifestConfig{
+		{Path: "public/.vite/manifest.json", Type: ViteManifestType},
+		{Path: "public/manifest.json", Type: WebpackManifestType},
+		{Path: "public/entries.json", Type: PassthroughManifestType},
+		{Path: "public/assets/manifest.json", Type: SymfonyManifestType},
+	}
+	for _, config := range valid {
+		if err := config.Validate(); err != nil {
+			t.Errorf("Config %v should be valid. Got: %v\n", config, err)
+		}
+	}
+
+	if err := (ManifestConfig{Path: "manifest.json", Type: ManifestType(42)}).Validate(); !errors.Is(err, ErrUnknownManifestType) {
+		t.Errorf("Unknown type should return ErrUnknownManifestType. Got: %v\n", err)
+	}
+	if err := (ManifestConfig{Type: ViteManifestType}).Validate(); err == nil {
+		t.Errorf("Empty path should be invalid\n")
+	}
+
+	a := NewAssetMapper()
+	if err := a.UseManifestBytes([]byte(`{}`), ManifestType(42)); !errors.Is(err, ErrUnknownManifestType) {
+		t.Errorf("UseManifestBytes should return ErrUnknownManifestType. Got: %v\n", err)
+	}
+}
+
+func TestSymfonyManifest(t *testing.T) {
+	path := writeManifest(t, `{
+  "app.js": "/assets/app-0a1b2c3d4e5f.js",
+  "styles/app.css": "/assets/styles/app-5f4e3d2c1b0a.css",
+  "images/logo.png": "/assets/images/logo-9a8b7c6d5e4f.png"
+}`)
+
+	for _, publicPath := range []string{"/", "/assets/"} {
+		a := NewAssetMapper()
+		a.PublicPath = publicPath
+		if err := a.UseManifest(ManifestConfig{Path: path, Type: SymfonyManifestType}); err != nil {
+			t.Fatal(err)
+		}
+
+		cases := map[string]string{
+			"app.js":          "/assets/app-0a1b2c3d4e5f.js",
+			"styles/app.css":  "/assets/styles/app-5f4e3d2c1b0a.css",
+			"images/logo.png": "/assets/images/logo-9a8b7c6d5e4f.png",
+		}
+		for logical, expected := range cases {
+			if result := a.Get(logical); expected != result {
+				t.Errorf("String should be equal for %s with PublicPath %s. Expected: %s\nGot:%s\n", logical, publicPath, expected, result)
+			}
+		}
+	}
+}
+
+func TestEntryHash(t *testing.T) {
+	load := func(manifest string) *AssetMapper {
+		a := NewAssetMapper()
+		if err := a.UseManifestBytes([]byte(manifest), ViteManifestType); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	before := load(multiEntryViteManifest)
+	after := load(strings.Replace(multiEntryViteManifest, "assets/admin-o2N34dPp.css", "assets/admin-BfX2d9kQ.css", 1))
+
+	if before.EntryHash("admin") == "" || before.EntryHash("admin") != before.EntryHash("admin") {
+		t.Errorf("EntryHash should be stable\n")
+	}
+	if before.EntryHash("admin") == after.EntryHash("admin") {
+		t.Errorf("EntryHash should change after entry asset change\n")
+	}
+	if before.EntryHash("app") != after.EntryHash("app") {
+		t.Errorf("EntryHash of unchanged entry should stay the same\n")
+	}
+	if before.EntryHash("missing") != "" {
+		t.Errorf("EntryHash of unknown entry should be empty\n")
+	}
+}
+
+func TestManifestAssetsHaveNoVersionQuery(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"assets/admin-o2N34dPp.css", "favicon.ico"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssetMapper()
+	a.Trim = dir + "/"
+	if err := a.UseManifest(ManifestConfig{Path: writeManifest(t, multiEntryViteManifest), Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ScanDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ScanDirSince(dir, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"src/app.js":                "/assets/app-CKgRTByK.js",
+		"src/app.js?debug=1":        "/assets/app-CKgRTByK.js?debug=1",
+		"assets/admin-o2N34dPp.css": "/assets/admin-o2N34dPp.css",
+		"favicon.ico":               "/favicon.ico?v=" + sha256Prefix("favicon.ico", 10),
+		"favicon.ico?debug=1":       "/favicon.ico?v=" + sha256Prefix("favicon.ico", 10) + "&debug=1",
+	}
+	for search, expected := range tests {
+		result := a.Get(search)
+		if expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", search, expected, result)
+		}
+	}
+}
+
+func TestMaxManifestSize(t *testing.T) {
+	records := []string{}
+	for i := range 100 {
+		records = append(records, fmt.Sprintf(`"src/chunk%d.js": {"file": "assets/chunk%d-CKgRTByK.js"}`, i, i))
+	}
+	oversized := "{" + strings.Join(records, ",") + "}"
+
+	a := NewAssetMapper()
+	a.MaxManifestSize = 1024
+	err := a.UseManifest(ManifestConfig{Path: writeManifest(t, oversized), Type: ViteManifestType})
+	if !errors.Is(err, ErrManifestTooLarge) {
+		t.Errorf("Oversized manifest should return ErrManifestTooLarge. Got: %v\n", err)
+	}
+	if err := a.UseManifestBytes([]byte(oversized), ViteManifestType); !errors.Is(err, ErrManifestTooLarge) {
+		t.Errorf("Oversized manifest should return ErrManifestTooLarge. Got: %v\n", err)
+	}
+
+	if err := a.UseManifest(ManifestConfig{Path: writeManifest(t, multiEntryViteManifest), Type: ViteManifestType}); err != nil {
+		t.Fatal(err)
+	}
+
+	a.MaxManifestSize = 0
+	if err := a.UseManifestBytes([]byte(oversized), ViteManifestType); err != nil {
+		t.Fatal(err)
+	}
+
+	a.MaxManifestSize = 1024
+	index := `{"manifests": []}` + strings.Repeat(" ", 2048)
+	if err := a.UseManifestIndex(writeManifest(t, index)); !errors.Is(err, ErrManifestTooLarge) {
+		t.Errorf("Oversized manifest index should return ErrManifestTooLarge. Got: %v\n", err)
+	}
+}
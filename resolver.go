@@ -0,0 +1,23 @@
+package asset
+
+import "html/template"
+
+// Resolver is subset of [AssetMapper] methods used by templates and handlers. Code depending on
+// Resolver instead of *AssetMapper can be tested with mock implementation.
+type Resolver interface {
+	Get(path string) string
+	Has(path string) bool
+	ScriptTag(path string, attrs ...string) (template.HTML, error)
+	LinkTag(path string, attrs ...string) (template.HTML, error)
+}
+
+var _ Resolver = (*AssetMapper)(nil)
+
+// Has reports whether path resolves to mapped asset, the same way as [AssetMapper.Get].
+func (a *AssetMapper) Has(path string) bool {
+	if isTraversal(path) {
+		return false
+	}
+	_, ok := a.lookup(path)
+	return ok
+}
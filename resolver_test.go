@@ -0,0 +1,65 @@
+package asset
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+type mockResolver struct {
+	urls map[string]string
+}
+
+func (m mockResolver) Get(path string) string {
+	return m.urls[path]
+}
+
+func (m mockResolver) Has(path string) bool {
+	_, ok := m.urls[path]
+	return ok
+}
+
+func (m mockResolver) ScriptTag(path string, attrs ...string) (template.HTML, error) {
+	return template.HTML(`<script src="` + m.urls[path] + `"></script>`), nil
+}
+
+func (m mockResolver) LinkTag(path string, attrs ...string) (template.HTML, error) {
+	return template.HTML(`<link href="` + m.urls[path] + `" rel="stylesheet"/>`), nil
+}
+
+// renderLayout is example of consuming code depending on Resolver.
+func renderLayout(r Resolver) (string, error) {
+	tpl, err := template.New("").Funcs(template.FuncMap{
+		"asset":     r.Get,
+		"hasAsset":  r.Has,
+		"scriptTag": r.ScriptTag,
+	}).Parse(`{{ scriptTag "app.js" }}{{ if hasAsset "logo.png" }}<img src="{{ asset "logo.png" }}">{{ end }}`)
+	if err != nil {
+		return "", err
+	}
+
+	out := strings.Builder{}
+	err = tpl.Execute(&out, nil)
+	return out.String(), err
+}
+
+func TestResolverMock(t *testing.T) {
+	result, err := renderLayout(mockResolver{urls: map[string]string{
+		"app.js":   "/app.js?v=test",
+		"logo.png": "/logo.png?v=test",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<script src="/app.js?v=test"></script><img src="/logo.png?v=test">`
+	if expected != result {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	a := NewAssetMapper()
+	a.Assets["logo.png"] = &Asset{Path: "logo.png", Hash: "123", PublicPath: "/"}
+	if !a.Has("logo.png") || a.Has("missing.png") || a.Has("../logo.png") {
+		t.Errorf("Has should report only mapped assets\n")
+	}
+}
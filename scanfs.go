@@ -0,0 +1,27 @@
+package asset
+
+import (
+	"io/fs"
+	"path"
+	"time"
+)
+
+// ScanFS walks root directory of fsys (e.g. [embed.FS] or [os.DirFS]) and maps all files to
+// AssetMapper. Asset paths are relative to root, same as in [AssetMapper.ScanRoots]. Scan options
+// work the same way as in [AssetMapper.ScanDir], except HashCache, which needs directory on disk.
+//
+// Example:
+//
+//	//go:embed public
+//	var public embed.FS
+//
+//	err := assetMapper.ScanFS(public, "public")
+func (a *AssetMapper) ScanFS(fsys fs.FS, root string) error {
+	root = path.Clean(root)
+	return a.scanFS(fsys, root, "", time.Time{}, func(name string) string {
+		return relName(root, name)
+	}, func(asset *Asset) error {
+		a.AddAsset(asset, false)
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package asset
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.js":      {Data: []byte("console.log(1)")},
+		"public/css/app.css": {Data: []byte("body { color: red; }")},
+		"templates/index":    {Data: []byte("<html></html>")},
+	}
+
+	a := NewAssetMapper()
+	if err := a.ScanFS(fsys, "public"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"app.js":      "/app.js?v=" + sha256Prefix("console.log(1)", 10),
+		"css/app.css": "/css/app.css?v=" + sha256Prefix("body { color: red; }", 10),
+	}
+	for path, expected := range cases {
+		if result := a.Get(path); expected != result {
+			t.Errorf("String should be equal for %s. Expected: %s\nGot:%s\n", path, expected, result)
+		}
+	}
+	if len(a.uniqueAssets()) != 2 {
+		t.Errorf("Only files in root should be mapped. Got: %d\n", len(a.uniqueAssets()))
+	}
+}
+
+func TestScanFSOptions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.css":                {Data: []byte(`@import "base.css"; body { margin: 0; }`)},
+		"public/base.css":               {Data: []byte("body { color: red; }")},
+		"public/assets/app-CKgRTByK.js": {Data: []byte("app")},
+	}
+	scan := func() *AssetMapper {
+		a := NewAssetMapper()
+		a.CSSImportHashing = true
+		a.SkipManifestOutputs = true
+		if err := a.UseManifestBytes([]byte(`{"src/app.js": {"file": "assets/app-CKgRTByK.js", "isEntry": true}}`), ViteManifestType); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.ScanFS(fsys, "public"); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	a := scan()
+	expected := "/assets/app-CKgRTByK.js"
+	if result := a.Get("assets/app-CKgRTByK.js"); expected != result {
+		t.Errorf("Manifest output should not be scanned. Expected: %s\nGot:%s\n", expected, result)
+	}
+
+	before := a.Get("app.css")
+	fsys["public/base.css"] = &fstest.MapFile{Data: []byte("body { color: blue; }")}
+	if after := scan().Get("app.css"); after == before {
+		t.Errorf("Importer hash should change after imported file change. Got: %s\n", after)
+	}
+}
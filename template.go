@@ -0,0 +1,76 @@
+package asset
+
+import "html/template"
+
+// TemplateFuncs returns template functions for asset mapper helpers, named the same way as in
+// documentation examples, e.g. "scriptTag", "asset" or "entryJsScripts".
+//
+// Example:
+//
+//	t := template.New("").Funcs(assetMapper.TemplateFuncs())
+func (a *AssetMapper) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"asset":             a.Get,
+		"hasAsset":          a.Has,
+		"bust":              a.Bust,
+		"scriptTag":         a.ScriptTag,
+		"linkTag":           a.LinkTag,
+		"imgTag":            a.ImgTag,
+		"preloadTag":        a.PreloadTag,
+		"preconnectTag":     a.PreconnectTag,
+		"iconTag":           a.IconTag,
+		"appleTouchIconTag": a.AppleTouchIconTag,
+		"webManifestTag":    a.WebManifestTag,
+		"importMapTag":      a.ImportMapTag,
+		"viteClientTag":     a.ViteClientTag,
+		"noScriptTag":       a.NoScriptTag,
+		"entryCss":          a.CSSEntry,
+		"entryJs":           a.JSEntry,
+		"entryOther":        a.OtherEntry,
+		"entryCssLinks":     a.CSSLinkTagsFromEntry,
+		"entryJsScripts":    a.JSScriptTagsFromEntry,
+		"entryHeadScripts":  a.HeadScriptsFromEntry,
+		"entryBodyScripts":  a.BodyScriptsFromEntry,
+		"entryHTML":         a.EntryHTML,
+		"inlineImportStyle": a.InlineImportStyle,
+		"entryPrefetch":     a.PrefetchEntryTags,
+		"entryHash":         a.EntryHash,
+		"assetsByType":      a.AssetsByType,
+		"assetsJson":        a.AssetsJSON,
+	}
+}
+
+// TemplateFuncsWithPrefix returns [AssetMapper.TemplateFuncs] with prefix added to every name,
+// so they don't clash with other functions in large template sets.
+//
+// Example:
+//
+//	t := template.New("").Funcs(assetMapper.TemplateFuncsWithPrefix("assets_"))
+//
+//	<!-- in template -->
+//	{{ assets_scriptTag "main.js" }}
+func (a *AssetMapper) TemplateFuncsWithPrefix(prefix string) template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, fn := range a.TemplateFuncs() {
+		funcs[prefix+name] = fn
+	}
+	return funcs
+}
+
+// TemplateFuncsSelect returns only requested functions of [AssetMapper.TemplateFuncs], to keep
+// template function maps small. Unknown names are skipped, so presence of helper in returned
+// map can be checked before use.
+//
+// Example:
+//
+//	t := template.New("").Funcs(assetMapper.TemplateFuncsSelect("asset", "scriptTag", "linkTag"))
+func (a *AssetMapper) TemplateFuncsSelect(names ...string) template.FuncMap {
+	all := a.TemplateFuncs()
+	funcs := template.FuncMap{}
+	for _, name := range names {
+		if fn, ok := all[name]; ok {
+			funcs[name] = fn
+		}
+	}
+	return funcs
+}
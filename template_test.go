@@ -0,0 +1,141 @@
+package asset
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestTemplateFuncsWithPrefix(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["main.js"] = &Asset{Path: "main.js", Hash: "123", PublicPath: "/"}
+
+	tpl, err := template.New("").Funcs(a.TemplateFuncsWithPrefix("assets_")).Parse(`{{ assets_scriptTag "main.js" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	if err := tpl.Execute(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<script src="/main.js?v=123"></script>`
+	if expected != out.String() {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, out.String())
+	}
+
+	if _, ok := a.TemplateFuncsWithPrefix("assets_")["scriptTag"]; ok {
+		t.Errorf("Unprefixed name should not be registered\n")
+	}
+}
+
+func TestTemplateFuncsSelect(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["main.js"] = &Asset{Path: "main.js", Hash: "123", PublicPath: "/"}
+
+	funcs := a.TemplateFuncsSelect("asset", "hasAsset", "pictureTag")
+	if len(funcs) != 2 {
+		t.Errorf("Only known requested funcs should be returned. Got: %v\n", funcs)
+	}
+	if _, ok := funcs["scriptTag"]; ok {
+		t.Errorf("Not requested func should not be registered\n")
+	}
+
+	tpl, err := template.New("").Funcs(funcs).Parse(`{{ if hasAsset "main.js" }}{{ asset "main.js" }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	if err := tpl.Execute(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/main.js?v=123"
+	if expected != out.String() {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, out.String())
+	}
+}
+
+func TestAssetsByTypeTemplateFunc(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["img/logo.png"] = &Asset{Path: "img/logo.png", File: "img/logo.png", Hash: "123", PublicPath: "/"}
+	a.Assets["img/hero.webp"] = &Asset{Path: "img/hero.webp", File: "img/hero.webp", Hash: "456", PublicPath: "/"}
+	a.Assets["app.js"] = &Asset{Path: "app.js", File: "app.js", Hash: "789", PublicPath: "/"}
+
+	tpl, err := template.New("").Funcs(a.TemplateFuncs()).Parse(`{{ range (assetsByType "image") }}{{ . }};{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	if err := tpl.Execute(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/img/hero.webp?v=456;/img/logo.png?v=123;"
+	if expected != out.String() {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, out.String())
+	}
+
+	if _, err := a.AssetsByType("images"); err == nil {
+		t.Errorf("Unknown kind should return error\n")
+	}
+}
+
+func TestBustTemplateFunc(t *testing.T) {
+	a := NewAssetMapper()
+	a.BuildVersion = "2024.05.1"
+
+	tpl, err := template.New("").Funcs(a.TemplateFuncs()).Parse(`<a href="{{ bust "/api/config" }}"></a>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	if err := tpl.Execute(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<a href="/api/config?v=2024.05.1"></a>`
+	if expected != out.String() {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, out.String())
+	}
+
+	cases := map[string]string{
+		"/api/config?lang=en": "/api/config?lang=en&v=2024.05.1",
+		"/docs#install":       "/docs?v=2024.05.1#install",
+	}
+	for u, expected := range cases {
+		if result := a.Bust(u); expected != result {
+			t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, result)
+		}
+	}
+
+	a.BuildVersion = ""
+	if result := a.Bust("/api/config"); result != "/api/config" {
+		t.Errorf("Url should be unchanged without BuildVersion. Got: %s\n", result)
+	}
+}
+
+func TestAssetsJSONTemplateFunc(t *testing.T) {
+	a := NewAssetMapper()
+	a.Assets["logo.svg"] = &Asset{Path: "logo.svg", Hash: "123", PublicPath: "/"}
+	a.Assets["worker.js"] = &Asset{Path: "worker.js", Hash: "456", PublicPath: "/"}
+
+	tpl, err := template.New("").Funcs(a.TemplateFuncs()).Parse(`<script>window.ASSETS = {{ assetsJson "logo.svg" "worker.js" "</script>" }};</script>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	if err := tpl.Execute(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<script>window.ASSETS = {"\u003c/script\u003e":"\u003c/script\u003e","logo.svg":"/logo.svg?v=123","worker.js":"/worker.js?v=456"};</script>`
+	if expected != out.String() {
+		t.Errorf("String should be equal. Expected: %s\nGot:%s\n", expected, out.String())
+	}
+}
@@ -1,11 +1,18 @@
 package asset
 
-import "regexp"
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
 
 var (
 	cssRe   = regexp.MustCompile(`\.css$`)
-	jsRe    = regexp.MustCompile(`\.js$`)
+	jsRe    = regexp.MustCompile(`(\.js|\.mjs|\.cjs)$`)
+	textRe  = regexp.MustCompile(`(\.css|\.js|\.mjs|\.cjs|\.json|\.svg|\.html|\.htm|\.txt|\.xml|\.map)$`)
 	imageRe = regexp.MustCompile(`(\.webp|\.jpg|\.jpeg|\.jpe|\.jfif|\.jif|\.png|\.gif|\.tiff|\.tif|\.svg|\.avif)$`)
+	fontRe  = regexp.MustCompile(`(\.woff2|\.woff|\.ttf|\.otf|\.eot)$`)
+	dataRe  = regexp.MustCompile(`(\.json|\.jsonld|\.geojson)$`)
 )
 
 func isCSS(path string) bool {
@@ -16,6 +23,87 @@ func isJS(path string) bool {
 	return jsRe.MatchString(path)
 }
 
+func isText(path string) bool {
+	return textRe.MatchString(path)
+}
+
 func isImage(path string) bool {
 	return imageRe.MatchString(path)
 }
+
+func isFont(path string) bool {
+	return fontRe.MatchString(path)
+}
+
+func isData(path string) bool {
+	return dataRe.MatchString(path)
+}
+
+// joinURL joins public path prefix and file path of manifest or asset without doubling slash
+// between them. Absolute file urls (with scheme or protocol relative) and root relative ones,
+// e.g. "/build/app.js", already point to final location and are returned unchanged.
+func joinURL(prefix, file string) string {
+	if prefix == "" || isAbsoluteURL(file) || strings.HasPrefix(file, "/") {
+		return file
+	}
+	return joinPath(prefix, file)
+}
+
+// joinPath joins url prefix and path without doubling slash between them.
+func joinPath(prefix, path string) string {
+	return strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// isAbsoluteURL reports whether file is url with scheme or protocol relative url.
+func isAbsoluteURL(file string) bool {
+	return strings.Contains(file, "://") || strings.HasPrefix(file, "//")
+}
+
+// fileKey returns file path without leading slash and version query, used as Assets key.
+func fileKey(file string) string {
+	file, _, _ = strings.Cut(file, "?")
+	file, _, _ = strings.Cut(file, "#")
+	return strings.TrimLeft(file, "/")
+}
+
+// cutQuery splits path before query string or fragment. ok is false if path has neither.
+func cutQuery(path string) (file, suffix string, ok bool) {
+	i := strings.IndexAny(path, "?#")
+	if i < 0 {
+		return path, "", false
+	}
+	return path[:i], path[i:], true
+}
+
+// withoutVersion removes "v" query parameters from suffix returned by cutQuery, so urls which
+// are already versioned don't get second version.
+func withoutVersion(suffix string) string {
+	query, fragment, hasFragment := strings.Cut(suffix, "#")
+	query, ok := strings.CutPrefix(query, "?")
+	if !ok {
+		return suffix
+	}
+
+	result := ""
+	params := slices.DeleteFunc(strings.Split(query, "&"), func(param string) bool {
+		name, _, _ := strings.Cut(param, "=")
+		return name == "v"
+	})
+	if len(params) > 0 {
+		result = "?" + strings.Join(params, "&")
+	}
+	if hasFragment {
+		result += "#" + fragment
+	}
+	return result
+}
+
+// isTraversal reports whether path contains ".." segment.
+func isTraversal(path string) bool {
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}